@@ -0,0 +1,41 @@
+// Command packr-migrations writes the migrations of a box to a directory
+// so they can be reviewed with other tools.
+//
+// Usage:
+//
+//	packr-migrations [-root dir] [-dir subdir] <box> <output>
+//
+// The box is the directory packr builds the box from.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fiskeben/packr-source-driver/driver"
+)
+
+func main() {
+	root := flag.String("root", "", "directory inside the box holding the migrations")
+	subdir := flag.String("dir", "", "subdirectory of root to export")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-root dir] [-dir subdir] <box> <output>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	d, err := driver.WithFS(os.DirFS(flag.Arg(0)), driver.WithRoot(*root), driver.WithSubdir(*subdir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to open box %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	if err := d.Export(flag.Arg(1)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,185 @@
+// Command packr-source inspects and validates the migrations of a box.
+//
+// Usage:
+//
+//	packr-source [-root dir] [-dir subdir] <box> list
+//	packr-source [-root dir] [-dir subdir] <box> validate
+//	packr-source [-root dir] [-dir subdir] <box> show <version> [up|down]
+//	packr-source [-root dir] [-dir subdir] <box> diff <other box>
+//	packr-source [-root dir] [-dir subdir] [-numbering policy] <box> new <name>
+//
+// The box is the directory packr builds the box from. list prints every
+// version with its identifier and the sizes of its files, validate
+// reports the problems found by Lint and exits with status 1 if there
+// are any, and show prints the body of a migration, the up migration
+// unless down is given. diff prints the versions added (+), removed (-)
+// and changed (~) in the other box, which uses the same directories,
+// and exits with status 1 if any were removed or changed, so a branch
+// can be checked to only append migrations. new creates the empty up
+// and down files of a migration called name in the migrations directory
+// of the box, numbered after the latest version following the numbering
+// policy (sequence, unix, datetime or any, which continues the policy of
+// the existing versions), and prints their paths.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/fiskeben/packr-source-driver/driver"
+)
+
+func main() {
+	root := flag.String("root", "", "directory inside the box holding the migrations")
+	subdir := flag.String("dir", "", "subdirectory of root to use")
+	numbering := flag.String("numbering", "any", "numbering policy of new migrations: sequence, unix, datetime or any")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "usage: %s [-root dir] [-dir subdir] <box> list|validate|show <version> [up|down]|diff <other box>|new <name>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	policy, err := driver.ParseNumberingPolicy(*numbering)
+	if err != nil {
+		fail(err)
+	}
+	d, err := driver.WithFS(os.DirFS(flag.Arg(0)), driver.WithRoot(*root), driver.WithSubdir(*subdir), driver.WithNumberingPolicy(policy))
+	if err != nil {
+		fail(fmt.Errorf("unable to open box %s: %v", flag.Arg(0), err))
+	}
+
+	args := flag.Args()[2:]
+	switch cmd := flag.Arg(1); {
+	case cmd == "list" && len(args) == 0:
+		err = list(d, os.Stdout)
+	case cmd == "validate" && len(args) == 0:
+		var ok bool
+		ok, err = validate(d, os.Stdout)
+		if err == nil && !ok {
+			os.Exit(1)
+		}
+	case cmd == "show" && (len(args) == 1 || len(args) == 2):
+		err = show(d, os.Stdout, args)
+	case cmd == "diff" && len(args) == 1:
+		var other *driver.Driver
+		other, err = driver.WithFS(os.DirFS(args[0]), driver.WithRoot(*root), driver.WithSubdir(*subdir))
+		if err != nil {
+			fail(fmt.Errorf("unable to open box %s: %v", args[0], err))
+		}
+		var ok bool
+		ok, err = diff(d, other, os.Stdout)
+		if err == nil && !ok {
+			os.Exit(1)
+		}
+	case cmd == "new" && len(args) == 1:
+		err = scaffold(d, filepath.Join(flag.Arg(0), *root, *subdir), args[0], os.Stdout)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// list prints a table of all versions.
+func list(d *driver.Driver, w io.Writer) error {
+	infos, err := d.Describe()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tIDENTIFIER\tUP\tDOWN")
+	for _, info := range infos {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", info.Version, info.Identifier, size(info.HasUp, info.UpSize), size(info.HasDown, info.DownSize))
+	}
+	return tw.Flush()
+}
+
+func size(present bool, n int64) string {
+	if !present {
+		return "-"
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// validate prints the problems found in the migrations and reports
+// whether there were none.
+func validate(d *driver.Driver, w io.Writer) (bool, error) {
+	problems, err := d.Lint()
+	if err != nil {
+		return false, err
+	}
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+	}
+	return len(problems) == 0, nil
+}
+
+// show prints the body of the migration named by args.
+func show(d *driver.Driver, w io.Writer, args []string) error {
+	version, err := strconv.ParseUint(args[0], 10, 0)
+	if err != nil {
+		return fmt.Errorf("invalid version '%s'", args[0])
+	}
+	read := d.ReadUp
+	if len(args) == 2 {
+		switch args[1] {
+		case "up":
+		case "down":
+			read = d.ReadDown
+		default:
+			return fmt.Errorf("invalid direction '%s'", args[1])
+		}
+	}
+	r, _, err := read(uint(version))
+	if err != nil {
+		return fmt.Errorf("unable to read migration %d: %v", version, err)
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// diff prints the differences between the migrations of d and other
+// and reports whether other only adds migrations.
+func diff(d, other *driver.Driver, w io.Writer) (bool, error) {
+	added, removed, changed, err := d.Diff(other)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range []struct {
+		mark  string
+		infos []driver.MigrationInfo
+	}{{"+", added}, {"-", removed}, {"~", changed}} {
+		for _, info := range c.infos {
+			fmt.Fprintf(w, "%s %d %s\n", c.mark, info.Version, info.Identifier)
+		}
+	}
+	return len(removed) == 0 && len(changed) == 0, nil
+}
+
+// scaffold creates the files of a new migration in dir and prints their
+// paths.
+func scaffold(d *driver.Driver, dir, name string, w io.Writer) error {
+	files, err := d.Scaffold(dir, name)
+	for _, file := range files {
+		fmt.Fprintln(w, file)
+	}
+	return err
+}
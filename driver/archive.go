@@ -0,0 +1,104 @@
+package driver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// WithArchive makes the driver read migrations from the archive name
+// inside the box instead of from the box itself, so a single file can
+// be embedded instead of every migration. Archives ending in .zip,
+// .tar, .tar.gz and .tgz are supported. The archive is read into memory
+// when the driver is created. Options like WithRoot then select a
+// directory inside the archive.
+func WithArchive(name string) Option {
+	return func(d *Driver) {
+		d.archive = name
+	}
+}
+
+// openArchive returns the files of the archive name in box.
+func openArchive(box Box, name string) (Box, error) {
+	r, err := openFile(box, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read archive %s: %v", name, err)
+	}
+	defer r.Close()
+
+	var files filesBox
+	switch lower := strings.ToLower(name); {
+	case strings.HasSuffix(lower, ".zip"):
+		files, err = readZip(r)
+	case strings.HasSuffix(lower, ".tar"):
+		files, err = readTar(r)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(r); err == nil {
+			files, err = readTar(gz)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported archive %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read archive %s: %v", name, err)
+	}
+	return files, nil
+}
+
+// readZip returns the files in the zip archive read from r.
+func readZip(r io.Reader) (filesBox, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := filesBox{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[cleanPath(f.Name)] = data
+	}
+	return files, nil
+}
+
+// readTar returns the regular files in the tar archive read from r.
+func readTar(r io.Reader) (filesBox, error) {
+	files := filesBox{}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[cleanPath(h.Name)] = data
+	}
+}
@@ -0,0 +1,101 @@
+package driver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	st "github.com/golang-migrate/migrate/v4/source/testing"
+)
+
+// archiveFiles meet the requirements of the golang-migrate driver tests.
+var archiveFiles = map[string]string{
+	"db/1_foobar.up.sql":   "1 up",
+	"db/1_foobar.down.sql": "1 down",
+	"db/3_foobar.up.sql":   "3 up",
+	"db/4_foobar.up.sql":   "4 up",
+	"db/4_foobar.down.sql": "4 down",
+	"db/5_foobar.down.sql": "5 down",
+	"db/7_foobar.up.sql":   "7 up",
+	"db/7_foobar.down.sql": "7 down",
+}
+
+func zipArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, body := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(body))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func tarGzArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	if err := w.WriteHeader(&tar.Header{Name: "db/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range files {
+		if err := w.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestWithArchive(t *testing.T) {
+	for name, archive := range map[string]string{
+		"assets/migrations.zip":    zipArchive(t, archiveFiles),
+		"assets/migrations.tar.gz": tarGzArchive(t, archiveFiles),
+	} {
+		t.Run(name, func(t *testing.T) {
+			box := newTestBox(map[string]string{name: archive, "assets/9_x.up.sql": "outside"})
+			d, err := WithInstance(box, WithArchive(name), WithRoot("db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			st.Test(t, d)
+		})
+	}
+
+	box := newTestBox(map[string]string{"migrations.rar": ""})
+	if _, err := WithInstance(box, WithArchive("migrations.rar")); err == nil {
+		t.Error("expected an error for an unsupported archive")
+	}
+	if _, err := WithInstance(box, WithArchive("missing.zip")); err == nil {
+		t.Error("expected an error for a missing archive")
+	}
+}
+
+func TestOpenWithArchive(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "migrations.zip"), []byte(zipArchive(t, archiveFiles)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := (&Driver{}).Open("packr://" + dir + "?archive=migrations.zip&root=db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Test(t, d)
+}
@@ -0,0 +1,103 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// WithBaseline squashes the history up to version into a baseline.
+// Versions below it are hidden, so a fresh database starts at version,
+// and ReadUp of version returns all up migrations up to and including
+// it concatenated like ReadUpBetween. The baseline has no down
+// migration. Databases already past version are unaffected, but
+// databases at a version below it can no longer be migrated by the
+// driver. Creating the driver fails if there is no up migration with
+// version.
+func WithBaseline(version uint) Option {
+	return func(d *Driver) {
+		d.baseline = version
+		d.baselineFile = ""
+	}
+}
+
+// WithBaselineFile is like WithBaseline, but the up migration of version
+// is read from file instead of being generated, for example a schema
+// dump maintained next to the migrations. The file is relative to the
+// directory the migrations are read from, and should be kept out of it,
+// like baseline/schema.sql, so it isn't considered a migration itself.
+func WithBaselineFile(version uint, file string) Option {
+	return func(d *Driver) {
+		d.baseline = version
+		d.baselineFile = file
+	}
+}
+
+// applyBaseline hides the versions below the baseline from idx and
+// records the migrations squashed into it.
+func (d *Driver) applyBaseline(idx *index) error {
+	if d.baseline == 0 {
+		return nil
+	}
+	if _, ok := idx.migrations.Up(d.baseline); !ok {
+		return fmt.Errorf("no up migration for baseline version %d", d.baseline)
+	}
+	kept := newMigrations()
+	v, ok := idx.migrations.First()
+	for ok {
+		up, hasUp := idx.migrations.Up(v)
+		switch {
+		case v > d.baseline:
+			for _, m := range idx.migrations.migrations[v] {
+				kept.Append(m)
+			}
+		case hasUp:
+			idx.baseline = append(idx.baseline, up)
+			if v == d.baseline {
+				kept.Append(up)
+			}
+		}
+		v, ok = idx.migrations.Next(v)
+	}
+	idx.migrations = kept
+	return nil
+}
+
+// isBaseline reports whether m is the baseline migration.
+func (d *Driver) isBaseline(m *source.Migration) bool {
+	return d.baseline != 0 && m.Version == d.baseline && m.Direction == source.Up
+}
+
+// isBaselineFile reports whether file, relative to the migration
+// directory, is the file given to WithBaselineFile.
+func (d *Driver) isBaselineFile(file string) bool {
+	return d.baselineFile != "" && file == cleanPath(d.baselineFile)
+}
+
+// baselineFiles returns the files the body of the baseline migration of
+// idx is read from: the baseline file, or the migrations squashed into
+// the baseline.
+func (d *Driver) baselineFiles(idx *index) []string {
+	if d.baselineFile != "" {
+		return []string{d.baselineFile}
+	}
+	var files []string
+	for _, m := range idx.baseline {
+		files = append(files, m.Raw)
+	}
+	return files
+}
+
+// baselineBody returns the body of the baseline migration m of idx.
+func (d *Driver) baselineBody(idx *index, m *source.Migration) (io.ReadCloser, error) {
+	read := func(m *source.Migration) (io.ReadCloser, error) {
+		return d.stored(idx.open, m)
+	}
+	if d.baselineFile != "" {
+		file := *m
+		file.Raw = d.baselineFile
+		return read(&file)
+	}
+	return &dumpReader{read: read, migs: idx.baseline}, nil
+}
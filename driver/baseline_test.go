@@ -0,0 +1,67 @@
+package driver
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestWithBaseline(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":     "CREATE TABLE a();",
+		"1_init.down.sql":   "DROP TABLE a;",
+		"2_users.up.sql":    "CREATE TABLE users();",
+		"2_users.down.sql":  "DROP TABLE users;",
+		"3_orders.up.sql":   "CREATE TABLE orders();",
+		"3_orders.down.sql": "DROP TABLE orders;",
+		"4_items.up.sql":    "CREATE TABLE items();",
+		"4_items.down.sql":  "DROP TABLE items;",
+	})
+	d, err := WithInstance(box, WithBaseline(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := d.First()
+	if err != nil || first != 3 {
+		t.Fatalf("expected the baseline as first version, got %d, %v", first, err)
+	}
+	if _, err := d.Prev(3); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no version before the baseline, got %v", err)
+	}
+	body, err := readUp(d)(3)
+	want := "-- 1_init.up.sql\nCREATE TABLE a();\n" +
+		"\n-- 2_users.up.sql\nCREATE TABLE users();\n" +
+		"\n-- 3_orders.up.sql\nCREATE TABLE orders();\n"
+	if err != nil || body != want {
+		t.Errorf("expected %q, got %q, %v", want, body, err)
+	}
+	if _, err := readDown(d)(3); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no down migration for the baseline, got %v", err)
+	}
+	if body, err := readDown(d)(4); err != nil || body != "DROP TABLE items;" {
+		t.Errorf("expected versions after the baseline to be unchanged, got %q, %v", body, err)
+	}
+
+	if _, err := WithInstance(box, WithBaseline(5)); err == nil {
+		t.Error("expected an error for a baseline without a migration")
+	}
+}
+
+func TestWithBaselineFile(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"db/1_init.up.sql":       "CREATE TABLE a();",
+		"db/2_users.up.sql":      "CREATE TABLE users();",
+		"db/3_orders.up.sql":     "CREATE TABLE orders();",
+		"db/baseline/schema.sql": "CREATE TABLE a(), users();",
+	})
+	d, err := WithInstance(box, WithRoot("db"), WithBaselineFile(2, "baseline/schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(2); err != nil || body != "CREATE TABLE a(), users();" {
+		t.Errorf("expected the baseline file, got %q, %v", body, err)
+	}
+	if body, err := readUp(d)(3); err != nil || body != "CREATE TABLE orders();" {
+		t.Errorf("expected %q, got %q, %v", "CREATE TABLE orders();", body, err)
+	}
+}
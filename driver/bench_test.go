@@ -0,0 +1,98 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// monolithBox returns a box of n files, one in ten of them migrations
+// in a db directory and the rest assets, like the box of a web application.
+func monolithBox(n int) memoryBox {
+	box := memoryBox{}
+	for i := 0; i < n; i++ {
+		if i%10 == 0 {
+			box[fmt.Sprintf("db/%d_migration.up.sql", i)] = []byte("select 1;")
+			continue
+		}
+		box[fmt.Sprintf("assets/img/%d/icon-%d.png", i%100, i)] = nil
+	}
+	return box
+}
+
+func BenchmarkWithInstance(b *testing.B) {
+	box := monolithBox(8000)
+	for _, bench := range []struct {
+		name string
+		opts []Option
+	}{
+		{"all", nil},
+		{"root", []Option{WithRoot("db")}},
+		{"extensions", []Option{WithExtensions(".sql")}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := WithInstance(box, bench.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkWithParallelism builds an index of a large box, checksumming
+// every migration for the prepare event, with a growing number of workers.
+func BenchmarkWithParallelism(b *testing.B) {
+	box := monolithBox(80000)
+	for i := 0; i < 80000; i += 10 {
+		box[fmt.Sprintf("db/%d_migration.up.sql", i)] = make([]byte, 16<<10)
+	}
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := WithInstance(box, WithParallelism(n), WithEvents(discardEvents{})); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type discardEvents struct{}
+
+func (discardEvents) OnPrepare(PrepareEvent) {}
+func (discardEvents) OnRead(ReadEvent)       {}
+func (discardEvents) OnSkip(SkipEvent)       {}
+
+// BenchmarkRead reads a large seed body, reporting the memory a read
+// allocates on top of the box holding it.
+func BenchmarkRead(b *testing.B) {
+	body := make([]byte, 8<<20)
+	for _, bench := range []struct {
+		name string
+		box  interface{}
+		opts []Option
+	}{
+		{"packr", newTestBox(map[string]string{"1_seed.up.sql": string(body)}), nil},
+		{"files", filesBox{"1_seed.up.sql": body}, nil},
+		{"cache", filesBox{"1_seed.up.sql": body}, []Option{WithBodyCache()}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			d, err := WithInstance(bench.box, bench.opts...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for i := 0; i < b.N; i++ {
+				r, _, err := d.ReadUp(1)
+				if err != nil {
+					b.Fatal(err)
+				}
+				io.Copy(ioutil.Discard, r)
+				r.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package driver
+
+import (
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobuffalo/packr"
+)
+
+// Box is the part of a packr box the driver reads migrations from.
+// Boxes of packr v2 (github.com/gobuffalo/packr/v2) and any packd.Box
+// implement it. Boxes of packr v1, which lack Find, are adapted by
+// WithInstance and AddBox, and so are Backend, fs.FS and http.FileSystem
+// implementations.
+type Box interface {
+	// List returns the names of all files in the box.
+	List() []string
+	// Find returns the contents of the named file.
+	Find(name string) ([]byte, error)
+}
+
+// Backend is a store of migration files that can be opened one by
+// one, for stores other than the built-in packr boxes, fs.FS and
+// http.FileSystem, like snapshots synced from S3, archives or custom
+// bundlers. Use it with WithBackend, or pass it to WithInstance or
+// AddBox; all parsing, validation and reading works the same for every
+// store.
+type Backend interface {
+	// List returns the names of all files in the store, with slashes
+	// as separators.
+	List() []string
+	// Open opens the named file for reading. If there is no such file,
+	// the error should match os.ErrNotExist.
+	Open(name string) (io.ReadCloser, error)
+}
+
+// backendBox adapts a Backend to the Box interface.
+type backendBox struct {
+	backend Backend
+}
+
+func (b backendBox) List() []string {
+	return b.backend.List()
+}
+
+func (b backendBox) Find(name string) ([]byte, error) {
+	r, err := b.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func (b backendBox) open(name string) (io.ReadCloser, error) {
+	return b.backend.Open(cleanPath(name))
+}
+
+func (b backendBox) size(name string) (int64, error) {
+	return measure(b.open, name)
+}
+
+// v1Box adapts a packr v1 box to the Box interface. packr v1 reads
+// whole files into memory, even when it resolves them from disk.
+type v1Box struct {
+	packr.Box
+}
+
+func (b v1Box) Find(name string) ([]byte, error) {
+	return b.MustBytes(name)
+}
+
+// fsBox adapts an fs.FS, such as an embed.FS, to the Box interface.
+type fsBox struct {
+	fsys fs.FS
+}
+
+func (b fsBox) List() []string {
+	var names []string
+	fs.WalkDir(b.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names
+}
+
+func (b fsBox) Find(name string) ([]byte, error) {
+	return fs.ReadFile(b.fsys, cleanPath(name))
+}
+
+func (b fsBox) Open(name string) (http.File, error) {
+	return http.FS(b.fsys).Open(name)
+}
+
+// httpFSBox adapts an http.FileSystem, like a directory of pkger or
+// statik, to the Box interface. Its files are listed by walking the
+// directories from the root.
+type httpFSBox struct {
+	fs http.FileSystem
+}
+
+func (b httpFSBox) List() []string {
+	var names []string
+	b.walk("", &names)
+	return names
+}
+
+// walk appends the names of the files below dir to names.
+// Directories that can't be read are left out.
+func (b httpFSBox) walk(dir string, names *[]string) {
+	f, err := b.fs.Open("/" + dir)
+	if err != nil {
+		return
+	}
+	infos, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return
+	}
+	for _, info := range infos {
+		name := path.Join(dir, info.Name())
+		if info.IsDir() {
+			b.walk(name, names)
+			continue
+		}
+		*names = append(*names, name)
+	}
+}
+
+func (b httpFSBox) Find(name string) ([]byte, error) {
+	f, err := b.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+func (b httpFSBox) Open(name string) (http.File, error) {
+	return b.fs.Open("/" + cleanPath(name))
+}
+
+// assetBox adapts the functions generated by go-bindata to the Box
+// interface.
+type assetBox struct {
+	names func() []string
+	asset func(name string) ([]byte, error)
+}
+
+func (b assetBox) List() []string {
+	return b.names()
+}
+
+func (b assetBox) Find(name string) ([]byte, error) {
+	return b.asset(cleanPath(name))
+}
+
+// filesBox is an in-memory Box keyed by clean file names.
+type filesBox map[string][]byte
+
+func (b filesBox) List() []string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (b filesBox) Find(name string) ([]byte, error) {
+	data, ok := b[cleanPath(name)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+// asBox returns box as a Box, or ErrNoBox if it isn't one.
+func asBox(box interface{}) (Box, error) {
+	switch b := box.(type) {
+	case packr.Box:
+		return v1Box{b}, nil
+	case *packr.Box:
+		if b == nil {
+			return nil, ErrNoBox
+		}
+		return v1Box{*b}, nil
+	case Box:
+		return b, nil
+	case Backend:
+		return backendBox{b}, nil
+	case fs.FS:
+		return fsBox{b}, nil
+	case http.FileSystem:
+		return httpFSBox{b}, nil
+	}
+	return nil, ErrNoBox
+}
+
+// listDir returns the names of the files in box below dir,
+// relative to dir. An empty dir lists the whole box.
+func listDir(box Box, dir string) []string {
+	dir = cleanPath(dir)
+	var names []string
+	for _, file := range box.List() {
+		if rel, ok := inCleanDir(cleanPath(file), dir); ok {
+			names = append(names, rel)
+		}
+	}
+	return names
+}
+
+// inDir reports whether the file name lies below the directory dir and
+// returns its path relative to dir. Matching is done on whole path
+// segments, so a dir of "postgres" doesn't match "postgresql-old/1.up.sql".
+// Both names may use either slashes or the OS path separator.
+func inDir(name, dir string) (string, bool) {
+	return inCleanDir(cleanPath(name), cleanPath(dir))
+}
+
+// inCleanDir is inDir for names already cleaned with cleanPath.
+func inCleanDir(name, dir string) (string, bool) {
+	if dir == "" {
+		return name, true
+	}
+	if !strings.HasPrefix(name, dir+"/") {
+		return "", false
+	}
+	return name[len(dir)+1:], true
+}
+
+// cleanPath normalizes a path inside a box to a slash separated
+// relative form without leading or trailing slashes.
+func cleanPath(p string) string {
+	p = path.Clean("/" + filepath.ToSlash(p))
+	return strings.TrimPrefix(p, "/")
+}
+
+// boxOpener returns an opener that reads migration bodies
+// from the root directory of box, see openFile.
+func boxOpener(box Box, root string) func(raw string) (io.ReadCloser, error) {
+	return func(raw string) (io.ReadCloser, error) {
+		return openFile(box, path.Join(root, raw))
+	}
+}
+
+// boxSizer returns a function reporting the size of files
+// in the root directory of box, see fileSize.
+func boxSizer(box Box, root string) func(raw string) (int64, error) {
+	return func(raw string) (int64, error) {
+		return fileSize(box, path.Join(root, raw))
+	}
+}
+
+// listedBox is a box listed once, when an index is built from it.
+// Files are indexed by their cleaned paths, so a box listing
+// db//1_init.up.sql or ./1_init.up.sql has to be asked for the file by
+// that name; listedBox maps the cleaned names to the listed ones.
+type listedBox struct {
+	Box
+	files []string
+	// unclean maps the cleaned names of files listed under other
+	// names, and not under their cleaned names, to the listed names.
+	unclean map[string]string
+}
+
+func newListedBox(box Box) *listedBox {
+	b := &listedBox{Box: box, files: box.List()}
+	listed := make(map[string]bool, len(b.files))
+	for _, file := range b.files {
+		listed[file] = true
+	}
+	for _, file := range b.files {
+		if clean := cleanPath(file); clean != file && !listed[clean] {
+			if b.unclean == nil {
+				b.unclean = map[string]string{}
+			}
+			b.unclean[clean] = file
+		}
+	}
+	return b
+}
+
+func (b *listedBox) List() []string {
+	return b.files
+}
+
+// listed returns the name b lists the file name under.
+func (b *listedBox) listed(name string) string {
+	if file, ok := b.unclean[name]; ok {
+		return file
+	}
+	return name
+}
+
+func (b *listedBox) Find(name string) ([]byte, error) {
+	return b.Box.Find(b.listed(name))
+}
+
+func (b *listedBox) open(name string) (io.ReadCloser, error) {
+	return openFile(b.Box, b.listed(name))
+}
+
+func (b *listedBox) size(name string) (int64, error) {
+	return fileSize(b.Box, b.listed(name))
+}
+
+// fileBox is implemented by boxes of this package that open and
+// measure files themselves.
+type fileBox interface {
+	open(name string) (io.ReadCloser, error)
+	size(name string) (int64, error)
+}
+
+// httpBox is implemented by boxes that open files as http.File,
+// such as packr boxes and fsBox.
+type httpBox interface {
+	Open(name string) (http.File, error)
+}
+
+// openFile opens the named file of box. Boxes that can open files
+// return them as they are, so files on disk are streamed instead of
+// being read into memory; other boxes are read with Find.
+// Note that packr v1 reads whole files even when they are on disk;
+// pass the directory with WithFS(os.DirFS(dir)) to stream them.
+func openFile(box Box, name string) (io.ReadCloser, error) {
+	switch b := box.(type) {
+	case fileBox:
+		return b.open(name)
+	case httpBox:
+		return b.Open(name)
+	}
+	data, err := box.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return newMemoryFile(data), nil
+}
+
+// fileSize returns the size of the named file of box. Boxes that can
+// open files report the size from Stat, other boxes are read.
+func fileSize(box Box, name string) (int64, error) {
+	switch b := box.(type) {
+	case fileBox:
+		return b.size(name)
+	case httpBox:
+		f, err := b.Open(name)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return fi.Size(), nil
+	}
+	data, err := box.Find(name)
+	return int64(len(data)), err
+}
@@ -0,0 +1,387 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	st "github.com/golang-migrate/migrate/v4/source/testing"
+)
+
+// memoryBox behaves like packd.MemoryBox of packr v2.
+type memoryBox map[string][]byte
+
+func (b memoryBox) List() []string {
+	var names []string
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (b memoryBox) Find(name string) ([]byte, error) {
+	data, ok := b[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func TestWithInstanceBox(t *testing.T) {
+	box := memoryBox{
+		"1_foobar.up.sql":   []byte("1 up"),
+		"1_foobar.down.sql": []byte("1 down"),
+		"3_foobar.up.sql":   []byte("3 up"),
+		"4_foobar.up.sql":   []byte("4 up"),
+		"4_foobar.down.sql": []byte("4 down"),
+		"5_foobar.down.sql": []byte("5 down"),
+		"7_foobar.up.sql":   []byte("7 up"),
+		"7_foobar.down.sql": []byte("7 down"),
+	}
+	d, err := WithInstance(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Test(t, d)
+
+	infos, err := d.Describe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if infos[0].DownSize != 6 {
+		t.Errorf("expected size 6, got %d", infos[0].DownSize)
+	}
+}
+
+func TestUncleanBoxNames(t *testing.T) {
+	box := memoryBox{
+		"db//1_init.up.sql":   []byte("1 up"),
+		"./db/2_goose.sql":    []byte("-- +goose Up\n2 up\n"),
+		"db/./3_users.up.sql": []byte("3 up"),
+	}
+	for _, opts := range [][]Option{{WithRoot("db")}, {WithRoot("db"), WithGooseFormat()}} {
+		d, err := WithInstance(box, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for v, err := d.First(); err == nil; v, err = d.Next(v) {
+			if _, err := readUp(d)(v); err != nil {
+				t.Errorf("expected version %d to be readable, got %v", v, err)
+			}
+			if _, err := d.Describe(); err != nil {
+				t.Errorf("expected sizes, got %v", err)
+			}
+		}
+	}
+}
+
+type listCountingBox struct {
+	memoryBox
+	lists int
+}
+
+func (b *listCountingBox) List() []string {
+	b.lists++
+	return b.memoryBox.List()
+}
+
+func TestUncleanNamesListedOnce(t *testing.T) {
+	box := &listCountingBox{memoryBox: memoryBox{
+		"db//1_init.up.sql": []byte("1 up"),
+		"db/./2_a.up.sql":   []byte("2 up"),
+	}}
+	d, err := WithInstance(box, WithRoot("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lists := box.lists
+	for _, v := range []uint{1, 2, 1, 2} {
+		if _, err := readUp(d)(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if box.lists != lists {
+		t.Errorf("expected reads not to list the box, got %d more listings", box.lists-lists)
+	}
+}
+
+func TestWithInstanceBoxPointer(t *testing.T) {
+	box := newTestBox(map[string]string{"1_a.up.sql": "a"})
+	d, err := WithInstance(&box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.First(); err != nil || v != 1 {
+		t.Errorf("expected first version 1, got %d, %v", v, err)
+	}
+}
+
+func TestWithInstanceNotABox(t *testing.T) {
+	if _, err := WithInstance("migrations"); err != ErrNoBox {
+		t.Errorf("expected ErrNoBox, got %v", err)
+	}
+}
+
+func TestWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/1_foobar.up.sql":   {Data: []byte("1 up")},
+		"migrations/1_foobar.down.sql": {Data: []byte("1 down")},
+		"migrations/3_foobar.up.sql":   {Data: []byte("3 up")},
+		"migrations/4_foobar.up.sql":   {Data: []byte("4 up")},
+		"migrations/4_foobar.down.sql": {Data: []byte("4 down")},
+		"migrations/5_foobar.down.sql": {Data: []byte("5 down")},
+		"migrations/7_foobar.up.sql":   {Data: []byte("7 up")},
+		"migrations/7_foobar.down.sql": {Data: []byte("7 down")},
+		"static/9_other.up.sql":        {Data: []byte("9 up")},
+	}
+	d, err := WithFS(fsys, WithRoot("migrations"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Test(t, d)
+
+	infos, err := d.Describe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if infos[0].UpSize != 4 || infos[0].DownSize != 6 {
+		t.Errorf("unexpected sizes: %+v", infos[0])
+	}
+
+	d, err = WithInstance(fsys, WithRoot("static"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.First(); err != nil || v != 9 {
+		t.Errorf("expected first version 9, got %d, %v", v, err)
+	}
+}
+
+// seedFS serves a large generated seed migration without holding it in
+// memory, and counts the bytes read from it.
+type seedFS struct {
+	fstest.MapFS
+	size int64
+	read *int64
+}
+
+func (f seedFS) Open(name string) (fs.File, error) {
+	if name != "1_seed.up.sql" {
+		return f.MapFS.Open(name)
+	}
+	return &seedFile{fs: f}, nil
+}
+
+type seedFile struct {
+	fs  seedFS
+	off int64
+}
+
+func (f *seedFile) Stat() (fs.FileInfo, error) { return seedInfo(f.fs.size), nil }
+func (f *seedFile) Close() error               { return nil }
+
+func (f *seedFile) Read(p []byte) (int, error) {
+	if f.off >= f.fs.size {
+		return 0, io.EOF
+	}
+	if rest := f.fs.size - f.off; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	for i := range p {
+		p[i] = 'x'
+	}
+	f.off += int64(len(p))
+	*f.fs.read += int64(len(p))
+	return len(p), nil
+}
+
+type seedInfo int64
+
+func (i seedInfo) Name() string       { return "1_seed.up.sql" }
+func (i seedInfo) Size() int64        { return int64(i) }
+func (i seedInfo) Mode() fs.FileMode  { return 0444 }
+func (i seedInfo) ModTime() time.Time { return time.Time{} }
+func (i seedInfo) IsDir() bool        { return false }
+func (i seedInfo) Sys() interface{}   { return nil }
+
+func TestStreamingRead(t *testing.T) {
+	var read int64
+	fsys := seedFS{
+		MapFS: fstest.MapFS{"1_seed.up.sql": {}},
+		size:  1 << 30,
+		read:  &read,
+	}
+	d, err := WithFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := d.Describe()
+	if err != nil || infos[0].UpSize != 1<<30 {
+		t.Fatalf("expected size from Stat, got %+v, %v", infos, err)
+	}
+
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	head := make([]byte, 16)
+	if _, err := io.ReadFull(r, head); err != nil || string(head) != "xxxxxxxxxxxxxxxx" {
+		t.Fatalf("expected the seed body, got %q, %v", head, err)
+	}
+	if read > 1<<20 {
+		t.Errorf("expected the body to be streamed, but %d bytes were read", read)
+	}
+}
+
+func TestWithFiles(t *testing.T) {
+	files := map[string][]byte{
+		"db/1_foobar.up.sql":    []byte("1 up"),
+		"db/1_foobar.down.sql":  []byte("1 down"),
+		"db/3_foobar.up.sql":    []byte("3 up"),
+		"db/4_foobar.up.sql":    []byte("4 up"),
+		"db/4_foobar.down.sql":  []byte("4 down"),
+		"db/5_foobar.down.sql":  []byte("5 down"),
+		"db/7_foobar.up.sql":    []byte("7 up"),
+		"/db/7_foobar.down.sql": []byte("7 down"),
+	}
+	d, err := WithFiles(files, WithRoot("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(files, "db/1_foobar.up.sql")
+	st.Test(t, d)
+}
+
+func TestWithAssetFunc(t *testing.T) {
+	// assets mimics the tables generated by go-bindata.
+	assets := map[string]string{
+		"migrations/1_foobar.up.sql":   "1 up",
+		"migrations/1_foobar.down.sql": "1 down",
+		"migrations/3_foobar.up.sql":   "3 up",
+		"migrations/4_foobar.up.sql":   "4 up",
+		"migrations/4_foobar.down.sql": "4 down",
+		"migrations/5_foobar.down.sql": "5 down",
+		"migrations/7_foobar.up.sql":   "7 up",
+		"migrations/7_foobar.down.sql": "7 down",
+	}
+	assetNames := func() []string {
+		names := make([]string, 0, len(assets))
+		for name := range assets {
+			names = append(names, name)
+		}
+		return names
+	}
+	asset := func(name string) ([]byte, error) {
+		data, ok := assets[name]
+		if !ok {
+			return nil, fmt.Errorf("asset %s not found", name)
+		}
+		return []byte(data), nil
+	}
+	d, err := WithAssetFunc(assetNames, asset, WithRoot("migrations"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Test(t, d)
+
+	if _, err := WithAssetFunc(nil, asset); err != ErrNoBox {
+		t.Errorf("expected ErrNoBox, got %v", err)
+	}
+}
+
+func TestWithHTTPFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "db"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range map[string]string{
+		"1_foobar.up.sql":   "1 up",
+		"1_foobar.down.sql": "1 down",
+		"3_foobar.up.sql":   "3 up",
+		"4_foobar.up.sql":   "4 up",
+		"4_foobar.down.sql": "4 down",
+		"5_foobar.down.sql": "5 down",
+		"7_foobar.up.sql":   "7 up",
+		"7_foobar.down.sql": "7 down",
+	} {
+		mustWriteFile(t, filepath.Join(dir, "db"), name, body)
+	}
+	mustWriteFile(t, dir, "9_outside.up.sql", "outside")
+
+	d, err := WithHTTPFileSystem(http.Dir(dir), WithRoot("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Test(t, d)
+
+	d, err = WithInstance(http.FS(os.DirFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(9); err != nil || body != "outside" {
+		t.Errorf("expected an http.FileSystem to be accepted as a box, got %q, %v", body, err)
+	}
+}
+
+// mapBackend is a Backend over a map of file contents.
+type mapBackend map[string]string
+
+func (b mapBackend) List() []string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (b mapBackend) Open(name string) (io.ReadCloser, error) {
+	body, ok := b[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return ioutil.NopCloser(strings.NewReader(body)), nil
+}
+
+func TestWithBackend(t *testing.T) {
+	b := mapBackend{
+		"db/1_foobar.up.sql":   "1 up",
+		"db/1_foobar.down.sql": "1 down",
+		"db/3_foobar.up.sql":   "3 up",
+		"db/4_foobar.up.sql":   "4 up",
+		"db/4_foobar.down.sql": "4 down",
+		"db/5_foobar.down.sql": "5 down",
+		"db/7_foobar.up.sql":   "7 up",
+		"db/7_foobar.down.sql": "7 down",
+	}
+	d, err := WithBackend(b, WithRoot("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Test(t, d)
+
+	d, err = WithInstance(mapBackend{"2_x.up.sql": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddBox(mapBackend{"3_y.up.sql": "y"}); err != nil {
+		t.Fatal(err)
+	}
+	infos, err := d.Describe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[1].UpSize != 1 {
+		t.Errorf("expected both backends with sizes, got %+v", infos)
+	}
+}
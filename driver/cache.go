@@ -0,0 +1,92 @@
+package driver
+
+import (
+	"io"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// WithBodyCache makes the driver keep the bodies it returns in memory,
+// after decompressing, decoding and rendering them, and serve later
+// reads of the same migration from memory. This saves the work when
+// migrations are read more than once, like in a dry run before applying
+// them. Bodies are then read in full when opened instead of streamed.
+// The cache is emptied when the index changes and when the driver is
+// closed.
+func WithBodyCache() Option {
+	return func(d *Driver) {
+		d.cache = &bodyCache{}
+	}
+}
+
+// bodyCache holds the bodies of migrations by version and direction.
+type bodyCache struct {
+	mu     sync.Mutex
+	bodies map[bodyKey][]byte
+	// bytes is the total size of bodies.
+	bytes int64
+	// generation counts the resets, so that bodies loaded from an
+	// index replaced meanwhile aren't cached.
+	generation int
+}
+
+type bodyKey struct {
+	version   uint
+	direction source.Direction
+}
+
+// body returns the cached body of m, loading it with load and reading
+// it with readAll if it isn't cached yet.
+func (c *bodyCache) body(m *source.Migration, load func(m *source.Migration) (io.ReadCloser, error), readAll func(r io.Reader) ([]byte, error)) (io.ReadCloser, error) {
+	key := bodyKey{m.Version, m.Direction}
+	c.mu.Lock()
+	data, ok := c.bodies[key]
+	generation := c.generation
+	c.mu.Unlock()
+	if !ok {
+		r, err := load(m)
+		if err != nil {
+			return nil, err
+		}
+		data, err = readAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		if c.generation == generation {
+			if c.bodies == nil {
+				c.bodies = map[bodyKey][]byte{}
+			}
+			if _, cached := c.bodies[key]; !cached {
+				c.bytes += int64(len(data))
+			}
+			c.bodies[key] = data
+		}
+		c.mu.Unlock()
+	}
+	return newMemoryFile(data), nil
+}
+
+// reset empties the cache.
+func (c *bodyCache) reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.bodies = nil
+	c.bytes = 0
+	c.generation++
+	c.mu.Unlock()
+}
+
+// size returns the total size of the cached bodies.
+func (c *bodyCache) size() int64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
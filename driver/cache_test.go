@@ -0,0 +1,47 @@
+package driver
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestWithBodyCache(t *testing.T) {
+	migs := []source.Migration{
+		{Version: 1, Identifier: "init", Direction: source.Up, Raw: "1_init.up.sql"},
+		{Version: 1, Identifier: "init", Direction: source.Down, Raw: "1_init.down.sql"},
+	}
+	opened := map[string]int{}
+	opener := func(raw string) (io.ReadCloser, error) {
+		opened[raw]++
+		return ioutil.NopCloser(strings.NewReader("body of " + raw)), nil
+	}
+	d, err := WithMigrations(migs, opener, WithBodyCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if body, err := readUp(d)(1); err != nil || body != "body of 1_init.up.sql" {
+			t.Fatalf("expected the up body, got %q, %v", body, err)
+		}
+	}
+	if body, err := readDown(d)(1); err != nil || body != "body of 1_init.down.sql" {
+		t.Fatalf("expected the down body, got %q, %v", body, err)
+	}
+	if opened["1_init.up.sql"] != 1 || opened["1_init.down.sql"] != 1 {
+		t.Errorf("expected each file to be opened once, got %v", opened)
+	}
+
+	if err := d.AddBox(newTestBox(map[string]string{"2_x.up.sql": ""})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readUp(d)(1); err != nil {
+		t.Fatal(err)
+	}
+	if opened["1_init.up.sql"] != 2 {
+		t.Errorf("expected the cache to be emptied when the index changes, got %v", opened)
+	}
+}
@@ -0,0 +1,195 @@
+package driver
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Manifest maps migration file names to the hex encoded SHA-256
+// checksums of their contents.
+type Manifest map[string]string
+
+// WriteTo writes m in the format of sha256sum, one line per file
+// sorted by name, so it can also be checked with sha256sum -c.
+func (m Manifest) WriteTo(w io.Writer) (int64, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var written int64
+	for _, name := range names {
+		n, err := fmt.Fprintf(w, "%s  %s\n", m[name], name)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// ParseManifest reads a manifest in the format written by Manifest.WriteTo.
+func ParseManifest(r io.Reader) (Manifest, error) {
+	m := Manifest{}
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			continue
+		}
+		fields := strings.SplitN(text, "  ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid manifest line %d", line)
+		}
+		sum, name := fields[0], strings.TrimPrefix(fields[1], "*")
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != 2*sha256.Size {
+			return nil, fmt.Errorf("invalid checksum on manifest line %d", line)
+		}
+		m[name] = sum
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WithManifest makes the driver verify the migrations against m when it
+// is created and fail if a file changed, is missing or isn't listed.
+func WithManifest(m Manifest) Option {
+	return func(d *Driver) {
+		d.manifest = m
+	}
+}
+
+// WithManifestFile is like WithManifest but reads the manifest from the
+// file name next to the migrations, so it can be embedded along with them.
+func WithManifestFile(name string) Option {
+	return func(d *Driver) {
+		d.manifestFile = name
+	}
+}
+
+// Checksums returns the manifest of the migrations known to the driver,
+// including the repeatable migrations. The checksums are computed over
+// the stored files.
+func (d *Driver) Checksums() (Manifest, error) {
+	if err := d.alive(); err != nil {
+		return nil, err
+	}
+	return d.checksumsOf(d.current())
+}
+
+// checksumsOf returns the manifest of the migrations of idx.
+func (d *Driver) checksumsOf(idx *index) (Manifest, error) {
+	var files []string
+	for _, mig := range idx.list() {
+		files = append(files, mig.Raw)
+	}
+	for _, mig := range idx.repeatables {
+		files = append(files, mig.Raw)
+	}
+	return d.checksums(idx.open, distinct(files))
+}
+
+// Fingerprint returns a hex encoded SHA-256 hash of the manifest of
+// the migrations, including the repeatable migrations and the files the
+// baseline is built from, so it identifies their names and stored
+// contents whatever order the box lists them in. Replicas built from
+// the same migrations report the same fingerprint, which makes it
+// suitable for health endpoints and logs. It is computed once per index
+// and is empty if a file can't be read or the driver is closed.
+func (d *Driver) Fingerprint() string {
+	d.mu.RLock()
+	fp, closed := d.fingerprint, d.closed
+	d.mu.RUnlock()
+	if closed || fp != "" {
+		return fp
+	}
+	idx := d.current()
+	m, err := d.checksumsOf(idx)
+	if err != nil {
+		return ""
+	}
+	baseline, err := d.checksums(idx.open, d.baselineFiles(idx))
+	if err != nil {
+		return ""
+	}
+	for name, sum := range baseline {
+		m[name] = sum
+	}
+	h := sha256.New()
+	m.WriteTo(h)
+	fp = hex.EncodeToString(h.Sum(nil))
+
+	d.mu.Lock()
+	if d.migrations == idx.migrations {
+		d.fingerprint = fp
+	}
+	d.mu.Unlock()
+	return fp
+}
+
+// verifyManifest checks the migrations of idx against the configured
+// manifest.
+func (d *Driver) verifyManifest(idx *index) error {
+	want := d.manifest
+	if d.manifestFile != "" {
+		r, err := idx.open(d.manifestFile)
+		if err != nil {
+			return fmt.Errorf("unable to read manifest %s: %v", d.manifestFile, err)
+		}
+		want, err = ParseManifest(r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("unable to read manifest %s: %v", d.manifestFile, err)
+		}
+	}
+	if want == nil {
+		return nil
+	}
+
+	got, err := d.checksumsOf(idx)
+	if err != nil {
+		return err
+	}
+	var mismatches []string
+	for name, sum := range got {
+		expected, ok := want[name]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, name+" (not in manifest)")
+		case expected != sum:
+			mismatches = append(mismatches, name+" (checksum mismatch)")
+		}
+	}
+	for name := range want {
+		if _, ok := got[name]; !ok {
+			mismatches = append(mismatches, name+" (missing)")
+		}
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return fmt.Errorf("migrations don't match manifest: %s", strings.Join(mismatches, ", "))
+	}
+	return nil
+}
+
+// checksum returns the hex encoded SHA-256 of the file raw.
+func checksum(open func(raw string) (io.ReadCloser, error), raw string) (string, error) {
+	r, err := open(raw)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,203 @@
+package driver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestChecksums(t *testing.T) {
+	d := newTestDriver(t, map[string]string{
+		"1_a.up.sql":   "a up",
+		"1_a.down.sql": "a down",
+	})
+	m, err := d.Checksums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Manifest{"1_a.up.sql": sha("a up"), "1_a.down.sql": sha("a down")}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("expected %v, got %v", want, m)
+	}
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := sha("a down") + "  1_a.down.sql\n" + sha("a up") + "  1_a.up.sql\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	parsed, err := ParseManifest(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, m) {
+		t.Errorf("expected %v after round trip, got %v", m, parsed)
+	}
+}
+
+func TestParseManifestInvalid(t *testing.T) {
+	for _, input := range []string{"abc", "xyz  1_a.up.sql", "abcd  1_a.up.sql"} {
+		if _, err := ParseManifest(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestWithManifest(t *testing.T) {
+	files := map[string]string{
+		"1_a.up.sql": "a up",
+		"2_b.up.sql": "b up",
+	}
+	manifest := Manifest{"1_a.up.sql": sha("a up"), "2_b.up.sql": sha("b up")}
+	if _, err := WithInstance(newTestBox(files), WithManifest(manifest)); err != nil {
+		t.Fatal(err)
+	}
+
+	files["2_b.up.sql"] = "tampered"
+	files["3_c.up.sql"] = "new"
+	delete(files, "1_a.up.sql")
+	_, err := WithInstance(newTestBox(files), WithManifest(manifest))
+	if err == nil {
+		t.Fatal("expected manifest mismatch")
+	}
+	for _, want := range []string{"1_a.up.sql (missing)", "2_b.up.sql (checksum mismatch)", "3_c.up.sql (not in manifest)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+}
+
+func TestFingerprintBaseline(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":       "CREATE TABLE a();",
+		"2_users.up.sql":      "CREATE TABLE users();",
+		"baseline/schema.sql": "CREATE TABLE a(); CREATE TABLE users();",
+	}
+	fingerprint := func(opt Option) string {
+		t.Helper()
+		d, err := WithInstance(newTestBox(files), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d.Fingerprint()
+	}
+	file := fingerprint(WithBaselineFile(2, "baseline/schema.sql"))
+	squashed := fingerprint(WithBaseline(2))
+
+	files["baseline/schema.sql"] = "DROP TABLE users;"
+	if fingerprint(WithBaselineFile(2, "baseline/schema.sql")) == file {
+		t.Error("expected a changed baseline file to change the fingerprint")
+	}
+	files["1_init.up.sql"] = "CREATE TABLE b();"
+	if fingerprint(WithBaseline(2)) == squashed {
+		t.Error("expected a changed squashed migration to change the fingerprint")
+	}
+}
+
+func TestWithManifestRepeatables(t *testing.T) {
+	files := map[string]string{
+		"1_a.up.sql":   "a up",
+		"R__views.sql": "CREATE VIEW v1 AS SELECT 1;",
+	}
+	manifest := Manifest{"1_a.up.sql": sha("a up"), "R__views.sql": sha(files["R__views.sql"])}
+	if _, err := WithInstance(newTestBox(files), WithManifest(manifest)); err != nil {
+		t.Fatal(err)
+	}
+
+	files["R__views.sql"] = "DROP TABLE users;"
+	_, err := WithInstance(newTestBox(files), WithManifest(manifest))
+	if err == nil || !strings.Contains(err.Error(), "R__views.sql (checksum mismatch)") {
+		t.Errorf("expected the tampered repeatable migration to be rejected, got %v", err)
+	}
+}
+
+func TestOpenWithManifest(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	mustWriteFile(t, tmpDir, "1_a.up.sql", "a up")
+	mustWriteFile(t, tmpDir, "checksums.sha256", sha("a up")+"  1_a.up.sql\n")
+
+	p := &Driver{}
+	if _, err := p.Open("packr://" + tmpDir + "?manifest=checksums.sha256&strict=true"); err != nil {
+		t.Fatal(err)
+	}
+
+	mustWriteFile(t, tmpDir, "stale.sha256", sha("old")+"  1_a.up.sql\n")
+	if _, err := p.Open("packr://" + tmpDir + "?manifest=stale.sha256"); err == nil {
+		t.Fatal("expected manifest mismatch")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":   "CREATE TABLE a();",
+		"1_init.down.sql": "DROP TABLE a;",
+		"2_users.up.sql":  "CREATE TABLE users();",
+	}
+	d, err := WithInstance(newTestBox(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := WithFiles(map[string][]byte{
+		"2_users.up.sql":  []byte("CREATE TABLE users();"),
+		"1_init.down.sql": []byte("DROP TABLE a;"),
+		"1_init.up.sql":   []byte("CREATE TABLE a();"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := d.Fingerprint()
+	if len(fp) != 64 {
+		t.Fatalf("expected a hex encoded SHA-256 hash, got %q", fp)
+	}
+	if other.Fingerprint() != fp {
+		t.Errorf("expected the same migrations to have the same fingerprint")
+	}
+	if d.Fingerprint() != fp {
+		t.Errorf("expected a stable fingerprint")
+	}
+
+	files["2_users.up.sql"] = "CREATE TABLE people();"
+	changed, err := WithInstance(newTestBox(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Fingerprint() == fp {
+		t.Error("expected a changed body to change the fingerprint")
+	}
+
+	files["R__views.sql"] = "CREATE VIEW v1 AS SELECT 1;"
+	views, err := WithInstance(newTestBox(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["R__views.sql"] = "DROP TABLE users;"
+	dropped, err := WithInstance(newTestBox(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if views.Fingerprint() == dropped.Fingerprint() {
+		t.Error("expected a changed repeatable migration to change the fingerprint")
+	}
+
+	d.Close()
+	if fp := d.Fingerprint(); fp != "" {
+		t.Errorf("expected no fingerprint after close, got %q", fp)
+	}
+}
@@ -0,0 +1,56 @@
+package driver
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestClose(t *testing.T) {
+	d, err := WithFiles(map[string][]byte{
+		"1_init.up.sql":   []byte("init"),
+		"1_init.down.sql": []byte("drop"),
+		"R__views.sql":    []byte("views"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := d.Close(); err != nil {
+			t.Fatalf("close %d: %v", i+1, err)
+		}
+	}
+
+	body, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil || string(body) != "init" {
+		t.Errorf("expected an open body to stay readable, got %q, %v", body, err)
+	}
+
+	calls := map[string]func() error{
+		"First":          func() error { _, err := d.First(); return err },
+		"Prev":           func() error { _, err := d.Prev(1); return err },
+		"Next":           func() error { _, err := d.Next(1); return err },
+		"ReadUp":         func() error { _, _, err := d.ReadUp(1); return err },
+		"ReadDown":       func() error { _, _, err := d.ReadDown(1); return err },
+		"List":           func() error { _, err := d.List(); return err },
+		"Describe":       func() error { _, err := d.Describe(); return err },
+		"Checksums":      func() error { _, err := d.Checksums(); return err },
+		"Validate":       func() error { return d.Validate() },
+		"ReadUpAll":      func() error { _, err := d.ReadUpAll(); return err },
+		"Repeatables":    func() error { _, err := d.Repeatables(); return err },
+		"ReadRepeatable": func() error { _, err := d.ReadRepeatable("views"); return err },
+		"Export":         func() error { return d.Export(t.TempDir()) },
+		"AddBox":         func() error { return d.AddBox(newTestBox(map[string]string{"2_x.up.sql": ""})) },
+		"Reload":         func() error { return d.Reload() },
+	}
+	for name, call := range calls {
+		if err := call(); err != ErrClosed {
+			t.Errorf("%s: expected ErrClosed, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// WithInstances returns a new driver serving the migrations of all boxes,
+// which are accepted in the same forms as by WithInstance. It fails if
+// two boxes contain a migration with the same version and direction.
+// Options like WithRoot only apply to the first box, see AddBox.
+func WithInstances(boxes ...interface{}) (*Driver, error) {
+	if len(boxes) == 0 {
+		return nil, ErrNoBox
+	}
+	return WithInstance(boxes[0], WithBoxes(boxes[1:]...))
+}
+
+// WithBoxes adds the migrations of further boxes to the driver as if
+// passed to AddBox once it is created.
+func WithBoxes(boxes ...interface{}) Option {
+	return func(d *Driver) {
+		d.extraBoxes = append(d.extraBoxes, boxes...)
+	}
+}
+
+// withBoxes returns o followed by the origins of the boxes configured
+// with WithBoxes.
+func (d *Driver) withBoxes(o origin) ([]origin, error) {
+	origins := []origin{o}
+	for _, box := range d.extraBoxes {
+		b, err := asBox(box)
+		if err != nil {
+			return nil, err
+		}
+		origins = append(origins, boxOrigin(d.folded(b), ""))
+	}
+	return origins, nil
+}
+
+// AddBox adds the migrations found in box to the driver.
+// The box is accepted in the same forms as by WithInstance.
+// The whole box is used; the root set with WithRoot only applies
+// to the box the driver was created with.
+// If any of them has the same version and direction as a migration
+// already known to the driver, nothing is added and the returned error
+// lists every collision. Likewise nothing is added if the combined set
+// fails one of the checks run when the driver is created, such as
+// WithLimits or WithManifest.
+// The combined set is visible to all methods as soon as AddBox returns.
+func (d *Driver) AddBox(box interface{}) error {
+	b, err := asBox(box)
+	if err != nil {
+		return err
+	}
+	d.build.Lock()
+	defer d.build.Unlock()
+	if err := d.alive(); err != nil {
+		return err
+	}
+	return d.rebuild(append(d.origins[:len(d.origins):len(d.origins)], boxOrigin(d.folded(b), "")))
+}
+
+// origin is a set of migrations merged into the index: either the files
+// in a directory of a box, which are listed again on every rebuild,
+// or a fixed set given to WithMigrations.
+type origin struct {
+	box  Box
+	root string
+	migs []*source.Migration
+	open func(raw string) (io.ReadCloser, error)
+	// size is nil if files are measured by reading them.
+	size func(raw string) (int64, error)
+}
+
+// boxOrigin returns the origin for the directory root of box.
+func boxOrigin(box Box, root string) origin {
+	return origin{box: box, root: root, open: boxOpener(box, root), size: boxSizer(box, root)}
+}
+
+// scan returns the migrations and repeatable migrations currently
+// found in o, passing the files it skips to skip.
+func (d *Driver) scan(o origin, skip func(file string, reason interface{})) ([]*source.Migration, []*source.Migration, error) {
+	if o.box == nil {
+		return o.migs, nil, nil
+	}
+	return d.parseBox(o.box, o.root, skip)
+}
+
+// index is an index along with the functions accessing its files.
+type index struct {
+	migrations *migrations
+	open       func(raw string) (io.ReadCloser, error)
+	size       func(raw string) (int64, error)
+	// baseline are the up migrations squashed into the baseline.
+	baseline []*source.Migration
+	// repeatables are sorted by name.
+	repeatables []*source.Migration
+	report      Report
+}
+
+// merge scans origins and builds an index of all their migrations
+// within the configured version range, except skipped versions.
+// Files not belonging to any migration, such as a manifest, are
+// opened through the first origin.
+// It fails if two migrations have the same version and direction,
+// listing every collision; the error unwraps to the first
+// *ErrDuplicateVersion. Of repeatable migrations with the same name,
+// the one of the first origin is kept.
+func (d *Driver) merge(origins []origin) (*index, error) {
+	idx := &index{migrations: newMigrations()}
+	owners := map[string]origin{}
+	var collisions []*ErrDuplicateVersion
+	skipped := map[uint]bool{}
+	repeatables := map[string]bool{}
+	inverse, err := d.historical()
+	if err != nil {
+		return nil, err
+	}
+	mapped := map[uint]bool{}
+	var left []SkippedFile
+	skip := func(file string, reason interface{}) {
+		d.skipped(file, reason)
+		left = append(left, SkippedFile{File: file, Reason: fmt.Sprint(reason)})
+	}
+	// Boxes are listed once per index, see listedBox.
+	scanned := make([]origin, len(origins))
+	for i, o := range origins {
+		if o.box != nil {
+			o = boxOrigin(newListedBox(o.box), o.root)
+		}
+		scanned[i] = o
+	}
+	for _, o := range scanned {
+		migs, repeats, err := d.scan(o, skip)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range repeats {
+			if repeatables[m.Identifier] {
+				continue
+			}
+			repeatables[m.Identifier] = true
+			idx.repeatables = append(idx.repeatables, m)
+			if _, ok := owners[m.Raw]; !ok {
+				owners[m.Raw] = o
+			}
+		}
+		for _, m := range migs {
+			if _, ok := inverse[m.Version]; ok {
+				mapped[m.Version] = true
+				m = remapped(inverse, m)
+			}
+			if d.skip[m.Version] {
+				skipped[m.Version] = true
+				skip(m.Raw, "version skipped")
+				continue
+			}
+			if !d.inRange(m) {
+				skip(m.Raw, "version out of range")
+				continue
+			}
+			if idx.migrations.Append(m) {
+				owners[m.Raw] = o
+				continue
+			}
+			existing, _ := lookup(idx.migrations, m.Version, m.Direction)
+			collisions = append(collisions, &ErrDuplicateVersion{
+				Version:   m.Version,
+				Direction: m.Direction,
+				Files:     []string{existing.Raw, m.Raw},
+			})
+		}
+	}
+	if len(collisions) > 0 {
+		msgs := make([]string, len(collisions))
+		for i, c := range collisions {
+			msgs[i] = c.Error()
+		}
+		return nil, &wrapped{"conflicting migrations: " + strings.Join(msgs, ", "), collisions[0]}
+	}
+	if err := d.checkIdentifiers(idx, owners); err != nil {
+		return nil, err
+	}
+	if err := d.checkSkipped(skipped); err != nil {
+		return nil, err
+	}
+	if err := d.checkRemapped(inverse, mapped); err != nil {
+		return nil, err
+	}
+	if err := d.applyBaseline(idx); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(idx.repeatables, func(i, j int) bool {
+		return idx.repeatables[i].Identifier < idx.repeatables[j].Identifier
+	})
+	idx.report = newReport(idx, left)
+
+	// Files next to a migration with an added extension, like its
+	// signature, belong to the origin of the migration.
+	owner := func(raw string) origin {
+		if o, ok := owners[raw]; ok {
+			return o
+		}
+		if o, ok := owners[strings.TrimSuffix(raw, path.Ext(raw))]; ok {
+			return o
+		}
+		return scanned[0]
+	}
+	idx.open = func(raw string) (io.ReadCloser, error) {
+		return owner(raw).open(raw)
+	}
+	idx.size = func(raw string) (int64, error) {
+		o := owner(raw)
+		if o.size == nil {
+			return measure(o.open, raw)
+		}
+		return o.size(raw)
+	}
+	return idx, nil
+}
+
+// rebuild replaces the index by one merged from origins, which become
+// the origins of the driver. The checks run when the driver is created
+// must pass on the new index before it replaces the old one.
+// The caller must hold d.build.
+func (d *Driver) rebuild(origins []origin) error {
+	_, span := d.startSpan(context.Background(), "packr.prepare")
+	idx, err := d.merge(origins)
+	if err == nil {
+		err = d.check(idx)
+	}
+	if err != nil {
+		endSpan(span, err)
+		return err
+	}
+	span.SetAttributes(indexAttributes(idx.migrations)...)
+	span.End()
+	d.origins = origins
+	d.swap(idx)
+	return nil
+}
+
+// current returns the index of the driver.
+func (d *Driver) current() *index {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return &index{
+		migrations:  d.migrations,
+		open:        d.open,
+		size:        d.size,
+		baseline:    d.baselineParts,
+		repeatables: d.repeatables,
+		report:      d.report,
+	}
+}
+
+// list returns all migrations of idx, in index order.
+func (idx *index) list() []*source.Migration {
+	var migs []*source.Migration
+	v, ok := idx.migrations.First()
+	for ok {
+		if m, found := idx.migrations.Up(v); found {
+			migs = append(migs, m)
+		}
+		if m, found := idx.migrations.Down(v); found {
+			migs = append(migs, m)
+		}
+		v, ok = idx.migrations.Next(v)
+	}
+	return migs
+}
+
+// swap makes idx the index of the driver.
+func (d *Driver) swap(idx *index) {
+	sums := d.eventSums(idx.migrations, idx.open)
+	d.mu.Lock()
+	d.migrations, d.open, d.size = idx.migrations, idx.open, idx.size
+	d.baselineParts, d.repeatables = idx.baseline, idx.repeatables
+	d.report, d.fingerprint, d.sums = idx.report, "", sums
+	d.mu.Unlock()
+	d.cache.reset()
+	d.indexed(idx)
+	d.prepareEvent(sums)
+}
+
+// raws returns the files of all migrations in migs, in index order.
+func raws(migs *migrations) []string {
+	var files []string
+	v, ok := migs.First()
+	for ok {
+		if m, found := migs.Up(v); found {
+			files = append(files, m.Raw)
+		}
+		if m, found := migs.Down(v); found {
+			files = append(files, m.Raw)
+		}
+		v, ok = migs.Next(v)
+	}
+	return files
+}
+
+// lookup returns the migration for version in direction dir.
+func lookup(migs *migrations, version uint, dir source.Direction) (*source.Migration, bool) {
+	if dir == source.Up {
+		return migs.Up(version)
+	}
+	return migs.Down(version)
+}
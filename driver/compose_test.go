@@ -0,0 +1,156 @@
+package driver
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAddBox(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{
+		"1_core.up.sql": "core 1",
+		"5_core.up.sql": "core 5",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddBox(newTestBox(map[string]string{
+		"3_plugin.up.sql":   "plugin 3",
+		"3_plugin.down.sql": "plugin 3 down",
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []uint
+	v, err := d.First()
+	for err == nil {
+		versions = append(versions, v)
+		v, err = d.Next(v)
+	}
+	if len(versions) != 3 || versions[0] != 1 || versions[1] != 3 || versions[2] != 5 {
+		t.Fatalf("expected versions [1 3 5], got %v", versions)
+	}
+
+	for version, want := range map[uint]string{1: "core 1", 3: "plugin 3", 5: "core 5"} {
+		r, _, err := d.ReadUp(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(r)
+		r.Close()
+		if string(body) != want {
+			t.Errorf("version %d: expected %q, got %q", version, want, body)
+		}
+	}
+
+	infos, err := d.Describe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if infos[1].DownSize != int64(len("plugin 3 down")) {
+		t.Errorf("unexpected size for added migration: %+v", infos[1])
+	}
+}
+
+func TestAddBoxCollision(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{"1_core.up.sql": "core"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.AddBox(newTestBox(map[string]string{
+		"1_other.up.sql": "other",
+		"2_new.up.sql":   "new",
+	}))
+	if err == nil {
+		t.Fatal("expected collision error")
+	}
+	if !strings.Contains(err.Error(), "1_core.up.sql") || !strings.Contains(err.Error(), "1_other.up.sql") {
+		t.Errorf("expected both files in error, got %q", err)
+	}
+	if _, err := d.Next(1); err == nil {
+		t.Error("expected failed AddBox to leave the index untouched")
+	}
+}
+
+func TestAddBoxConcurrent(t *testing.T) {
+	d := newTestDriver(t, map[string]string{"1_a.up.sql": "a"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if r, _, err := d.ReadUp(1); err == nil {
+					r.Close()
+				}
+				d.Next(1)
+			}
+		}()
+	}
+	if err := d.AddBox(newTestBox(map[string]string{"2_b.up.sql": "b"})); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if v, err := d.Next(1); err != nil || v != 2 {
+		t.Fatalf("expected next version 2, got %d, %v", v, err)
+	}
+}
+
+func TestWithInstances(t *testing.T) {
+	core := newTestBox(map[string]string{
+		"1_core.up.sql":   "core 1",
+		"1_core.down.sql": "core 1 down",
+	})
+	svc := memoryBox{"2_svc.up.sql": []byte("svc 2")}
+	d, err := WithInstances(core, svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(2); err != nil || body != "svc 2" {
+		t.Errorf("expected svc body, got %q, %v", body, err)
+	}
+	if body, err := readDown(d)(1); err != nil || body != "core 1 down" {
+		t.Errorf("expected core body, got %q, %v", body, err)
+	}
+
+	conflicting := memoryBox{"1_svc.up.sql": nil}
+	_, err = WithInstances(core, svc, conflicting)
+	if err == nil || !strings.Contains(err.Error(), "1_core.up.sql and 1_svc.up.sql") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+
+	if _, err := WithInstances(); err != ErrNoBox {
+		t.Errorf("expected ErrNoBox, got %v", err)
+	}
+	if _, err := WithInstances(core, "not a box"); err != ErrNoBox {
+		t.Errorf("expected ErrNoBox, got %v", err)
+	}
+}
+
+func TestWithBoxes(t *testing.T) {
+	d := newTestDriver(t, map[string]string{"1_a.up.sql": "a"}, WithBoxes(memoryBox{"2_b.up.sql": []byte("b")}))
+	if v, err := d.Next(1); err != nil || v != 2 {
+		t.Errorf("expected next version 2, got %d, %v", v, err)
+	}
+}
+
+func TestAddBoxChecked(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{"1_core.up.sql": "core"}),
+		WithLimits(Limits{MaxBytes: 8}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.AddBox(newTestBox(map[string]string{"2_big.up.sql": "far too large"}))
+	if err == nil || !strings.Contains(err.Error(), "2_big.up.sql") {
+		t.Fatalf("expected limits error naming the file, got %v", err)
+	}
+	if _, err := d.Next(1); err == nil {
+		t.Error("expected failed AddBox to leave the index untouched")
+	}
+	if err := d.Reload(); err != nil {
+		t.Errorf("expected reload to succeed after rejected AddBox, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package driver
+
+import (
+	"compress/gzip"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// defaultDecompressors are the decompressors every driver starts with.
+var defaultDecompressors = map[string]func(r io.Reader) (io.Reader, error){
+	".gz": func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	},
+}
+
+// WithDecompressor makes ReadUp and ReadDown decompress files with the
+// extension ext, such as ".br", using decompress. The version and
+// direction are parsed from the full name, so 5_seed.up.sql.br is the
+// up migration of version 5. Gzip compressed files (".gz") are
+// decompressed by default; passing a nil decompress for ".gz" serves
+// them as stored.
+//
+// Brotli can be supported with github.com/andybalholm/brotli:
+//
+//	driver.WithDecompressor(".br", func(r io.Reader) (io.Reader, error) {
+//		return brotli.NewReader(r), nil
+//	})
+func WithDecompressor(ext string, decompress func(r io.Reader) (io.Reader, error)) Option {
+	return func(d *Driver) {
+		if d.decompressors == nil {
+			d.decompressors = copyDecompressors(defaultDecompressors)
+		}
+		if decompress == nil {
+			delete(d.decompressors, ext)
+			return
+		}
+		d.decompressors[ext] = decompress
+	}
+}
+
+func copyDecompressors(m map[string]func(r io.Reader) (io.Reader, error)) map[string]func(r io.Reader) (io.Reader, error) {
+	c := make(map[string]func(r io.Reader) (io.Reader, error), len(m))
+	for ext, fn := range m {
+		c[ext] = fn
+	}
+	return c
+}
+
+// decompressor returns the decompressor for the file name raw, if any.
+func (d *Driver) decompressor(raw string) (func(r io.Reader) (io.Reader, error), bool) {
+	decompressors := d.decompressors
+	if decompressors == nil {
+		decompressors = defaultDecompressors
+	}
+	fn, ok := decompressors[path.Ext(strings.TrimSuffix(raw, encryptedExt))]
+	return fn, ok
+}
+
+// decompress replaces the body of a compressed file with its
+// decompressed content.
+func (d *Driver) decompress(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error) {
+	fn, ok := d.decompressor(m.Raw)
+	if !ok {
+		return r, nil
+	}
+	dr, err := fn(r)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser{Reader: dr, Closer: closerFunc(func() error {
+		if c, ok := dr.(io.Closer); ok {
+			c.Close()
+		}
+		return r.Close()
+	})}, nil
+}
+
+// withoutCompressionExt returns raw without the extensions
+// of its encryption and compression formats, if any.
+func (d *Driver) withoutCompressionExt(raw string) string {
+	raw = strings.TrimSuffix(raw, encryptedExt)
+	if _, ok := d.decompressor(raw); ok {
+		return strings.TrimSuffix(raw, path.Ext(raw))
+	}
+	return raw
+}
+
+// contentExt returns the extension of the file name raw, ignoring
+// the extensions of its encryption and compression formats and of
+// templates.
+func (d *Driver) contentExt(raw string) string {
+	raw = d.withoutCompressionExt(raw)
+	if d.isTemplate(raw) {
+		raw = strings.TrimSuffix(raw, templateExt)
+	}
+	return path.Ext(raw)
+}
+
+// closerFunc turns a function into an io.Closer.
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
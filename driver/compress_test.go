@@ -0,0 +1,83 @@
+package driver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"testing"
+)
+
+func gzipped(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestDecompressGzip(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"5_seed.up.sql.gz":    gzipped(t, "INSERT INTO seed;"),
+		"5_seed.down.sql":     "DELETE FROM seed;",
+		"6_corrupt.up.sql.gz": "not gzip",
+	})
+	d, err := WithInstance(box, WithExtensions(".sql"), WithStrictParsing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := readUp(d)(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "INSERT INTO seed;" {
+		t.Errorf("expected decompressed body, got %q", body)
+	}
+	if body, err := readDown(d)(5); err != nil || body != "DELETE FROM seed;" {
+		t.Errorf("expected plain down body, got %q, %v", body, err)
+	}
+	if _, _, err := d.ReadUp(6); err == nil {
+		t.Error("expected error for corrupt gzip data")
+	}
+}
+
+func TestWithDecompressor(t *testing.T) {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte("zlib body"))
+	w.Close()
+
+	gz := gzipped(t, "gzip body")
+	box := newTestBox(map[string]string{
+		"1_a.up.sql.z":  buf.String(),
+		"2_b.up.sql.gz": gz,
+	})
+	d, err := WithInstance(box,
+		WithDecompressor(".z", func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }),
+		WithDecompressor(".gz", nil),
+		WithExtensions(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(1); err != nil || body != "zlib body" {
+		t.Errorf("expected zlib body, got %q, %v", body, err)
+	}
+	if body, err := readUp(d)(2); err != nil || body != gz {
+		t.Errorf("expected gzip file to be served as stored, got %q, %v", body, err)
+	}
+
+	// other drivers keep the defaults
+	d, err = WithInstance(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(2); err != nil || body != "gzip body" {
+		t.Errorf("expected gzip body, got %q, %v", body, err)
+	}
+}
@@ -0,0 +1,116 @@
+package driver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentUse reads migrations from several goroutines while the
+// index changes. Run it with -race.
+func TestConcurrentUse(t *testing.T) {
+	dir := t.TempDir()
+	for v := 1; v <= 5; v++ {
+		mustWriteFile(t, dir, fmt.Sprintf("%d_m.up.sql", v), fmt.Sprint(v))
+		mustWriteFile(t, dir, fmt.Sprintf("%d_m.down.sql", v), fmt.Sprint(v))
+	}
+	d, err := WithFS(os.DirFS(dir), WithReadConcurrency(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 100)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 20; n++ {
+				if err := walk(d); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := d.Describe(); err != nil {
+					errs <- err
+					return
+				}
+				d.Stats()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for v := 6; v <= 15; v++ {
+			name := filepath.Join(dir, fmt.Sprintf("%d_m.up.sql", v))
+			if err := ioutil.WriteFile(name, []byte(fmt.Sprint(v)), 0644); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := d.reload(false); err != nil {
+				errs <- err
+				return
+			}
+			box := newTestBox(map[string]string{fmt.Sprintf("%d_m.up.sql", v+100): fmt.Sprint(v + 100)})
+			if err := d.AddBox(box); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// walk reads every migration of d, checking that each body is its
+// version.
+func walk(d *Driver) error {
+	v, err := d.First()
+	for err == nil {
+		for _, read := range []func(uint) (string, error){readUp(d), readDown(d)} {
+			body, err := read(v)
+			if err == os.ErrNotExist {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if body != fmt.Sprint(v) {
+				return fmt.Errorf("expected body %d, got %q", v, body)
+			}
+		}
+		v, err = d.Next(v)
+	}
+	if err != os.ErrNotExist {
+		return err
+	}
+	return nil
+}
+
+func TestConcurrentBodies(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{
+		"1_a.up.sql": "a",
+		"2_b.up.sql": "b",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r1.Close()
+	if err := d.AddBox(newTestBox(map[string]string{"3_c.up.sql": "c"})); err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(r1)
+	if err != nil || string(body) != "a" {
+		t.Errorf("expected a body opened before a change to keep reading its file, got %q, %v", body, err)
+	}
+}
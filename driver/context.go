@@ -0,0 +1,60 @@
+package driver
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// ReadUpContext is like ReadUp but gives up waiting for a reader slot
+// (see WithReadConcurrency) when ctx is done, and the returned body
+// fails with ctx.Err() once ctx is done.
+func (d *Driver) ReadUpContext(ctx context.Context, version uint) (r io.ReadCloser, identifier string, err error) {
+	d.mu.RLock()
+	m, ok := d.migrations.Up(version)
+	closed := d.closed
+	d.mu.RUnlock()
+	if closed {
+		return nil, "", ErrClosed
+	}
+	if !ok {
+		return nil, "", os.ErrNotExist
+	}
+	r, err = d.read(ctx, m)
+	if err != nil {
+		return nil, "", err
+	}
+	return r, m.Identifier, nil
+}
+
+// ReadDownContext is the ReadDown counterpart of ReadUpContext.
+func (d *Driver) ReadDownContext(ctx context.Context, version uint) (r io.ReadCloser, identifier string, err error) {
+	d.mu.RLock()
+	m, ok := d.migrations.Down(version)
+	closed := d.closed
+	d.mu.RUnlock()
+	if closed {
+		return nil, "", ErrClosed
+	}
+	if !ok {
+		return nil, "", os.ErrNotExist
+	}
+	r, err = d.read(ctx, m)
+	if err != nil {
+		return nil, "", err
+	}
+	return r, m.Identifier, nil
+}
+
+// ctxReader fails reads once its context is done.
+type ctxReader struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.ReadCloser.Read(p)
+}
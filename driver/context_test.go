@@ -0,0 +1,58 @@
+package driver
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+func TestReadUpContextCanceled(t *testing.T) {
+	d := newTestDriver(t, map[string]string{"1_a.up.sql": "1 up", "1_a.down.sql": "1 down"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := d.ReadUpContext(ctx, 1); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, _, err := d.ReadDownContext(ctx, 1); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestReadUpContextCanceledWhileReading(t *testing.T) {
+	d := newTestDriver(t, map[string]string{"1_a.up.sql": "1 up"})
+	ctx, cancel := context.WithCancel(context.Background())
+	r, _, err := d.ReadUpContext(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	cancel()
+	if _, err := ioutil.ReadAll(r); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestReadUpContextWaitingForSlot(t *testing.T) {
+	d := newTestDriver(t, map[string]string{"1_a.up.sql": "1 up", "1_a.down.sql": "1 down"}, WithReadConcurrency(1))
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, _, err := d.ReadDownContext(ctx, 1)
+		done <- err
+	}()
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	r.Close()
+	r, _, err = d.ReadDownContext(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("expected the slot to be free, got %v", err)
+	}
+	r.Close()
+}
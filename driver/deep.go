@@ -0,0 +1,126 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxChecker checks the SQL of a migration body, see
+// WithDeepValidation. CheckSQL is a dialect-agnostic one.
+type SyntaxChecker func(body string) error
+
+// WithDeepValidation makes the driver read every migration when it is
+// created and fail if a body holds no statements, only whitespace or
+// comments, or isn't valid UTF-8, naming the offending files. An empty
+// down migration would otherwise turn a rollback into a silent no-op.
+// If syntax isn't nil, every body must pass it as well. Bodies are
+// checked as ReadUp and ReadDown serve them.
+func WithDeepValidation(syntax SyntaxChecker) Option {
+	return func(d *Driver) {
+		d.deep = true
+		d.syntax = syntax
+	}
+}
+
+// checkBodies checks every migration body of idx, see WithDeepValidation.
+func (d *Driver) checkBodies(idx *index) error {
+	if !d.deep {
+		return nil
+	}
+	var invalid []string
+	for _, m := range idx.list() {
+		r, err := d.loadFrom(idx, m)
+		if err != nil {
+			return err
+		}
+		body, err := d.readAll(r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("unable to read migration %s: %v", m.Raw, err)
+		}
+		switch {
+		case !utf8.Valid(body):
+			invalid = append(invalid, m.Raw+" (invalid UTF-8)")
+		case len(splitBody(string(body))) == 0:
+			invalid = append(invalid, m.Raw+" (empty)")
+		case d.syntax != nil:
+			if err := d.syntax(string(body)); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s (%v)", m.Raw, err))
+			}
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	sort.Strings(invalid)
+	return fmt.Errorf("invalid migrations: %s", strings.Join(invalid, ", "))
+}
+
+// CheckSQL is a SyntaxChecker catching mistakes any SQL dialect rejects:
+// unterminated quoted strings, identifiers, block comments and
+// PostgreSQL dollar-quoted bodies, and unbalanced parentheses.
+// Quotes may be escaped by doubling them or, in PostgreSQL E'...' strings
+// and MySQL strings, with a backslash; a body is only rejected if it is
+// malformed whichever way its backslashes are read.
+func CheckSQL(body string) error {
+	err := checkSQL(body, false)
+	if err != nil && checkSQL(body, true) == nil {
+		return nil
+	}
+	return err
+}
+
+// checkSQL is CheckSQL with backslashes escaping quotes if backslash
+// is set, see skipQuoted.
+func checkSQL(body string, backslash bool) error {
+	depth := 0
+	for i := 0; i < len(body); {
+		switch c := body[i]; {
+		case c == '-' && strings.HasPrefix(body[i:], "--"):
+			i = skipPast(body, i+2, "\n")
+		case c == '/' && strings.HasPrefix(body[i:], "/*"):
+			if !strings.Contains(body[i+2:], "*/") {
+				return fmt.Errorf("unterminated comment at line %d", line(body, i))
+			}
+			i = skipPast(body, i+2, "*/")
+		case c == '\'' || c == '"' || c == '`':
+			end, ok := skipQuoted(body, i, backslash)
+			if !ok {
+				return fmt.Errorf("unterminated %c quote at line %d", c, line(body, i))
+			}
+			i = end
+		case c == '$':
+			tag, ok := dollarTag(body, i)
+			if !ok {
+				i++
+				continue
+			}
+			if !strings.Contains(body[i+len(tag):], tag) {
+				return fmt.Errorf("unterminated %s body at line %d", tag, line(body, i))
+			}
+			i = skipPast(body, i+len(tag), tag)
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			if depth == 0 {
+				return fmt.Errorf("unbalanced ) at line %d", line(body, i))
+			}
+			depth--
+			i++
+		default:
+			i++
+		}
+	}
+	if depth > 0 {
+		return fmt.Errorf("%d unclosed (", depth)
+	}
+	return nil
+}
+
+// line returns the number of the line of body holding offset i.
+func line(body string, i int) int {
+	return strings.Count(body[:i], "\n") + 1
+}
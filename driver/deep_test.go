@@ -0,0 +1,89 @@
+package driver
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithDeepValidation(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":     "CREATE TABLE a();",
+		"1_init.down.sql":   "DROP TABLE a;",
+		"2_users.up.sql":    "CREATE TABLE users();",
+		"2_users.down.sql":  " \n",
+		"3_orders.up.sql":   "CREATE TABLE orders(name text DEFAULT '\xff');",
+		"3_orders.down.sql": "-- nothing to undo\n",
+		"4_items.up.sql":    "CREATE TABLE items(;",
+	}
+	if _, err := WithInstance(newTestBox(files)); err != nil {
+		t.Fatalf("expected no validation by default, got %v", err)
+	}
+
+	_, err := WithInstance(newTestBox(files), WithDeepValidation(nil))
+	want := "invalid migrations: 2_users.down.sql (empty), 3_orders.down.sql (empty), 3_orders.up.sql (invalid UTF-8)"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+
+	_, err = WithInstance(newTestBox(files), WithDeepValidation(CheckSQL))
+	if err == nil || !strings.Contains(err.Error(), "4_items.up.sql (1 unclosed ()") {
+		t.Errorf("expected the syntax error of version 4, got %v", err)
+	}
+
+	rejected := errors.New("not allowed")
+	syntax := func(body string) error {
+		if strings.HasPrefix(body, "DROP") {
+			return rejected
+		}
+		return nil
+	}
+	_, err = WithInstance(newTestBox(map[string]string{
+		"1_init.up.sql":   "CREATE TABLE a();",
+		"1_init.down.sql": "DROP TABLE a;",
+	}), WithDeepValidation(syntax))
+	if err == nil || err.Error() != "invalid migrations: 1_init.down.sql (not allowed)" {
+		t.Errorf("expected the checker's error, got %v", err)
+	}
+}
+
+func TestCheckSQL(t *testing.T) {
+	for body, want := range map[string]string{
+		"CREATE TABLE a (id int);":                  "",
+		"INSERT INTO a VALUES ('it''s (');":         "",
+		"-- (\nSELECT 1; /* ) */":                   "",
+		"CREATE FUNCTION f() AS $$ SELECT ')'; $$;": "",
+		"SELECT $1 + 1;":                            "",
+		"INSERT INTO a VALUES ('it\\'s (');":        "",
+		"INSERT INTO a VALUES (E'\\'', 'C:\\');":    "",
+		"INSERT INTO a VALUES ('C:\\', ')');":       "",
+		"SELECT 'open;":                             "unterminated ' quote at line 1",
+		"SELECT 1;\nSELECT \"open;":                 "unterminated \" quote at line 2",
+		"SELECT 1; /* open":                         "unterminated comment at line 1",
+		"CREATE FUNCTION f() AS $body$\nSELECT 1;":  "unterminated $body$ body at line 1",
+		"SELECT (1));":                              "unbalanced ) at line 1",
+		"CREATE TABLE a (id int, name (text);":      "1 unclosed (",
+	} {
+		err := CheckSQL(body)
+		if want == "" && err != nil || want != "" && (err == nil || err.Error() != want) {
+			t.Errorf("CheckSQL(%q): expected %q, got %v", body, want, err)
+		}
+	}
+}
+
+func TestDeepValidationURL(t *testing.T) {
+	for v, syntax := range map[string]bool{"true": false, "sql": true} {
+		opts, err := queryOptions(map[string][]string{"deep": {v}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := &Driver{}
+		d.apply(opts)
+		if !d.deep || (d.syntax != nil) != syntax {
+			t.Errorf("deep=%s: unexpected configuration", v)
+		}
+	}
+	if _, err := queryOptions(map[string][]string{"deep": {"very"}}); err == nil {
+		t.Error("expected an error for an invalid deep value")
+	}
+}
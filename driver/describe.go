@@ -0,0 +1,134 @@
+package driver
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// MigrationInfo describes one migration version.
+type MigrationInfo struct {
+	Version    uint   `json:"version"`
+	Identifier string `json:"identifier"`
+	HasUp      bool   `json:"hasUp"`
+	HasDown    bool   `json:"hasDown"`
+	UpSize     int64  `json:"upSize"`
+	DownSize   int64  `json:"downSize"`
+}
+
+// MigrationFile describes one migration file.
+type MigrationFile struct {
+	Version    uint             `json:"version"`
+	Direction  source.Direction `json:"direction"`
+	Identifier string           `json:"identifier"`
+	Raw        string           `json:"raw"`
+	Size       int64            `json:"size"`
+	// Metadata holds the fields of the header comment of the file,
+	// like "-- ticket: OPS-1234", with lower-cased keys.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// List returns every migration file known to the driver, in version
+// order with the up migration of a version before the down migration.
+// The header of each body is read for its metadata.
+func (d *Driver) List() ([]MigrationFile, error) {
+	if err := d.alive(); err != nil {
+		return nil, err
+	}
+	var files []MigrationFile
+	for _, m := range d.list() {
+		size, err := d.sizeOf(m.Raw)
+		if err != nil {
+			return nil, err
+		}
+		meta, err := d.metadata(m)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, MigrationFile{
+			Version:    m.Version,
+			Direction:  m.Direction,
+			Identifier: m.Identifier,
+			Raw:        m.Raw,
+			Size:       size,
+			Metadata:   meta,
+		})
+	}
+	return files, nil
+}
+
+// Describe returns information about every migration version,
+// sorted by version. Sizes are those of the stored files;
+// bodies are only read when the source can't report sizes otherwise.
+func (d *Driver) Describe() ([]MigrationInfo, error) {
+	var infos []MigrationInfo
+	err := d.Iterate(func(info MigrationInfo) bool {
+		infos = append(infos, info)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// Iterate calls fn with information about every migration version in
+// version order, like Describe, until fn returns false. Unlike a loop
+// over First and Next it needs no os.ErrNotExist check at the end, and
+// an empty driver simply doesn't call fn. The versions are those known
+// when Iterate is called; the error is that of a size that couldn't be
+// determined.
+func (d *Driver) Iterate(fn func(MigrationInfo) bool) error {
+	if err := d.alive(); err != nil {
+		return err
+	}
+	var info *MigrationInfo
+	for _, m := range d.list() {
+		if info != nil && info.Version != m.Version {
+			if !fn(*info) {
+				return nil
+			}
+			info = nil
+		}
+		size, err := d.sizeOf(m.Raw)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			info = &MigrationInfo{Version: m.Version, Identifier: m.Identifier}
+		}
+		if m.Direction == source.Up {
+			info.HasUp = true
+			info.UpSize = size
+		} else {
+			info.HasDown = true
+			info.DownSize = size
+		}
+	}
+	if info != nil {
+		fn(*info)
+	}
+	return nil
+}
+
+// sizeOf returns the size of the file stored at raw.
+func (d *Driver) sizeOf(raw string) (int64, error) {
+	d.mu.RLock()
+	open, size := d.open, d.size
+	d.mu.RUnlock()
+	if size != nil {
+		return size(raw)
+	}
+	return measure(open, raw)
+}
+
+// measure returns the size of raw by reading it through open.
+func measure(open func(raw string) (io.ReadCloser, error), raw string) (int64, error) {
+	r, err := open(raw)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return io.Copy(ioutil.Discard, r)
+}
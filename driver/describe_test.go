@@ -0,0 +1,118 @@
+package driver
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestDescribe(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":      "CREATE TABLE a();",
+		"1_init.down.sql":    "DROP TABLE a;",
+		"3_users.up.sql":     "CREATE TABLE users();",
+		"5_cleanup.down.sql": "DELETE;",
+		"README.md":          "not a migration",
+	})
+	d, err := WithInstance(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := d.Describe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MigrationInfo{
+		{Version: 1, Identifier: "init", HasUp: true, HasDown: true, UpSize: 17, DownSize: 13},
+		{Version: 3, Identifier: "users", HasUp: true, UpSize: 21},
+		{Version: 5, Identifier: "cleanup", HasDown: true, DownSize: 7},
+	}
+	if !reflect.DeepEqual(infos, want) {
+		t.Errorf("expected %+v, got %+v", want, infos)
+	}
+
+	data, err := json.Marshal(infos[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"version":3,"identifier":"users","hasUp":true,"hasDown":false,"upSize":21,"downSize":0}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestDescribeWithMigrations(t *testing.T) {
+	d := newTestDriver(t, map[string]string{"2_x.down.sql": "four"})
+	infos, err := d.Describe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MigrationInfo{{Version: 2, Identifier: "x", HasDown: true, DownSize: 4}}
+	if !reflect.DeepEqual(infos, want) {
+		t.Errorf("expected %+v, got %+v", want, infos)
+	}
+}
+
+func TestList(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"db/1_init.up.sql":   "CREATE TABLE a();",
+		"db/1_init.down.sql": "DROP TABLE a;",
+		"db/2_users.up.sql":  "CREATE TABLE users();",
+	})
+	d, err := WithInstance(box, WithRoot("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MigrationFile{
+		{Version: 1, Direction: source.Up, Identifier: "init", Raw: "1_init.up.sql", Size: 17},
+		{Version: 1, Direction: source.Down, Identifier: "init", Raw: "1_init.down.sql", Size: 13},
+		{Version: 2, Direction: source.Up, Identifier: "users", Raw: "2_users.up.sql", Size: 21},
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("expected %+v, got %+v", want, files)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":   "",
+		"1_init.down.sql": "",
+		"3_users.up.sql":  "",
+		"5_orders.up.sql": "",
+	})
+	d, err := WithInstance(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []uint
+	err = d.Iterate(func(info MigrationInfo) bool {
+		versions = append(versions, info.Version)
+		return info.Version < 3
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint{1, 3}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("expected to stop after %v, got %v", want, versions)
+	}
+
+	empty, err := WithInstance(newTestBox(map[string]string{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = empty.Iterate(func(MigrationInfo) bool {
+		t.Error("expected no migrations")
+		return true
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,107 @@
+package driver
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Diff compares the migrations of the driver with those of other, such
+// as a driver built from another box, by version and by the SHA-256
+// checksums of the bodies both serve. It returns the versions only
+// other has, the versions only the driver has, and the versions whose
+// identifier or bodies differ, each described as other has them except
+// for removed versions, and sorted by version. Sizes in the descriptions
+// are those of the bodies served.
+//
+// To check that a branch only appends migrations, diff its driver with
+// the one of the base branch and require removed and changed to be
+// empty.
+func (d *Driver) Diff(other source.Driver) (added, removed, changed []MigrationInfo, err error) {
+	if err := d.alive(); err != nil {
+		return nil, nil, nil, err
+	}
+	mine, err := digest(d)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	theirs, err := digest(other)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	i, j := 0, 0
+	for i < len(mine) || j < len(theirs) {
+		switch {
+		case j == len(theirs) || i < len(mine) && mine[i].info.Version < theirs[j].info.Version:
+			removed = append(removed, mine[i].info)
+			i++
+		case i == len(mine) || theirs[j].info.Version < mine[i].info.Version:
+			added = append(added, theirs[j].info)
+			j++
+		default:
+			if mine[i].info.Identifier != theirs[j].info.Identifier || mine[i].up != theirs[j].up || mine[i].down != theirs[j].down {
+				changed = append(changed, theirs[j].info)
+			}
+			i++
+			j++
+		}
+	}
+	return added, removed, changed, nil
+}
+
+// versionDigest describes a version along with the checksums of its
+// bodies.
+type versionDigest struct {
+	info     MigrationInfo
+	up, down [sha256.Size]byte
+}
+
+// digest reads every migration of drv, in version order.
+func digest(drv source.Driver) ([]versionDigest, error) {
+	var digests []versionDigest
+	v, err := drv.First()
+	for err == nil {
+		vd := versionDigest{info: MigrationInfo{Version: v}}
+		if vd.info.HasUp, vd.info.UpSize, vd.up, err = digestBody(drv.ReadUp, v, &vd.info.Identifier); err != nil {
+			return nil, err
+		}
+		if vd.info.HasDown, vd.info.DownSize, vd.down, err = digestBody(drv.ReadDown, v, &vd.info.Identifier); err != nil {
+			return nil, err
+		}
+		digests = append(digests, vd)
+		v, err = drv.Next(v)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return digests, nil
+}
+
+// digestBody reads the body of version with read and returns whether
+// there is one, its size and checksum. The identifier of the body is
+// stored in identifier unless one is set already.
+func digestBody(read func(uint) (io.ReadCloser, string, error), version uint, identifier *string) (bool, int64, [sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	r, id, err := read(version)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, 0, sum, nil
+	}
+	if err != nil {
+		return false, 0, sum, err
+	}
+	defer r.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return false, 0, sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	if *identifier == "" {
+		*identifier = id
+	}
+	return true, n, sum, nil
+}
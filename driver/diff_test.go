@@ -0,0 +1,53 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	base, err := WithInstance(newTestBox(map[string]string{
+		"1_init.up.sql":    "CREATE TABLE a();",
+		"1_init.down.sql":  "DROP TABLE a;",
+		"2_users.up.sql":   "CREATE TABLE users();",
+		"3_orders.up.sql":  "CREATE TABLE orders();",
+		"4_renamed.up.sql": "",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hotfix, err := WithInstance(newTestBox(map[string]string{
+		"1_init.up.sql":   "CREATE TABLE a();",
+		"1_init.down.sql": "DROP TABLE a;",
+		"3_orders.up.sql": "CREATE TABLE orders(id int);",
+		"4_other.up.sql":  "",
+		"5_fix.up.sql":    "UPDATE;",
+		"5_fix.down.sql":  "",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	added, removed, changed, err := base.Diff(hotfix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []MigrationInfo{{Version: 5, Identifier: "fix", HasUp: true, UpSize: 7, HasDown: true}}; !reflect.DeepEqual(added, want) {
+		t.Errorf("expected added %+v, got %+v", want, added)
+	}
+	if want := []MigrationInfo{{Version: 2, Identifier: "users", HasUp: true, UpSize: 21}}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("expected removed %+v, got %+v", want, removed)
+	}
+	want := []MigrationInfo{
+		{Version: 3, Identifier: "orders", HasUp: true, UpSize: 28},
+		{Version: 4, Identifier: "other", HasUp: true},
+	}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("expected changed %+v, got %+v", want, changed)
+	}
+
+	added, removed, changed, err = base.Diff(base)
+	if err != nil || added != nil || removed != nil || changed != nil {
+		t.Errorf("expected no differences, got %v, %v, %v, %v", added, removed, changed, err)
+	}
+}
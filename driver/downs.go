@@ -0,0 +1,65 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// downPolicy is the rule for down migrations set by WithRequiredDowns
+// or WithoutDowns.
+type downPolicy struct {
+	required  bool
+	forbidden bool
+	// below limits required downs to lower versions, if not 0.
+	below uint
+}
+
+// WithRequiredDowns makes the driver fail when it is created if an up
+// migration with a version below below has no down migration, naming
+// the offending files, so a missing rollback is found before it is
+// needed in an incident. With below 0, every up migration needs a down
+// migration. It replaces WithoutDowns.
+func WithRequiredDowns(below uint) Option {
+	return func(d *Driver) {
+		d.downs = downPolicy{required: true, below: below}
+	}
+}
+
+// WithoutDowns makes the driver fail when it is created if there are
+// any down migrations, for teams that only roll forward. It replaces
+// WithRequiredDowns.
+func WithoutDowns() Option {
+	return func(d *Driver) {
+		d.downs = downPolicy{forbidden: true}
+	}
+}
+
+// checkDowns checks the migrations of idx against the down policy.
+func (d *Driver) checkDowns(idx *index) error {
+	if !d.downs.required && !d.downs.forbidden {
+		return nil
+	}
+	migs := idx.migrations
+	var offending []string
+	for _, m := range idx.list() {
+		switch {
+		case d.downs.forbidden && m.Direction == source.Down:
+			offending = append(offending, m.Raw)
+		case d.downs.required && m.Direction == source.Up && (d.downs.below == 0 || m.Version < d.downs.below):
+			if _, ok := migs.Down(m.Version); !ok {
+				offending = append(offending, m.Raw)
+			}
+		}
+	}
+	if len(offending) == 0 {
+		return nil
+	}
+	sort.Strings(offending)
+	if d.downs.forbidden {
+		return fmt.Errorf("down migrations are not allowed: %s", strings.Join(offending, ", "))
+	}
+	return fmt.Errorf("up migrations without a down migration: %s", strings.Join(offending, ", "))
+}
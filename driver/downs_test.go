@@ -0,0 +1,71 @@
+package driver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithRequiredDowns(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":      "",
+		"1_init.down.sql":    "",
+		"2_users.up.sql":     "",
+		"5_orders.up.sql":    "",
+		"6_cleanup.down.sql": "",
+	}
+	_, err := WithInstance(newTestBox(files), WithRequiredDowns(0))
+	if err == nil {
+		t.Fatal("expected error for missing down migrations")
+	}
+	if want := "up migrations without a down migration: 2_users.up.sql, 5_orders.up.sql"; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err)
+	}
+
+	_, err = WithInstance(newTestBox(files), WithRequiredDowns(5))
+	if err == nil || !strings.Contains(err.Error(), "2_users.up.sql") || strings.Contains(err.Error(), "5_orders") {
+		t.Errorf("expected only versions below 5 to need downs, got %v", err)
+	}
+	if _, err := WithInstance(newTestBox(files), WithRequiredDowns(2)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithoutDowns(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":   "",
+		"1_init.down.sql": "",
+		"2_users.up.sql":  "",
+	}
+	_, err := WithInstance(newTestBox(files), WithoutDowns())
+	if err == nil || err.Error() != "down migrations are not allowed: 1_init.down.sql" {
+		t.Errorf("expected error naming the down migration, got %v", err)
+	}
+	delete(files, "1_init.down.sql")
+	if _, err := WithInstance(newTestBox(files), WithoutDowns()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDownPolicyQuery(t *testing.T) {
+	for v, want := range map[string]downPolicy{
+		"required":      {required: true},
+		"required:1000": {required: true, below: 1000},
+		"forbidden":     {forbidden: true},
+		"any":           {},
+	} {
+		opts, err := queryOptions(map[string][]string{"downs": {v}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := &Driver{}
+		d.apply(opts)
+		if d.downs != want {
+			t.Errorf("expected %+v for %s, got %+v", want, v, d.downs)
+		}
+	}
+	for _, v := range []string{"sometimes", "required:x", "forbidden:3"} {
+		if _, err := queryOptions(map[string][]string{"downs": {v}}); err == nil {
+			t.Errorf("expected error for downs %s", v)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package driver
+
+import (
+	"sort"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// DriftReport compares the migrations of a driver with the versions
+// applied to a database, see Verify.
+type DriftReport struct {
+	// Missing are applied versions without an up migration in the
+	// driver, as when a binary was built from another branch.
+	Missing []uint `json:"missing"`
+	// Skipped are versions of the driver below the highest applied one
+	// that weren't applied, and won't be since they sort too early.
+	Skipped []uint `json:"skipped"`
+	// Pending are versions of the driver above the highest applied one,
+	// which the next migration applies.
+	Pending []uint `json:"pending"`
+}
+
+// Drifted reports whether the database and the driver disagree, that
+// is whether versions are missing or skipped. Pending versions are
+// expected before a deploy.
+func (r DriftReport) Drifted() bool {
+	return len(r.Missing) > 0 || len(r.Skipped) > 0
+}
+
+// Verify compares the up migrations of the driver with the versions
+// applied to a database, as recorded by the migration tool, in any
+// order. All slices of the report are sorted.
+func (d *Driver) Verify(applied []uint) (DriftReport, error) {
+	var report DriftReport
+	if err := d.alive(); err != nil {
+		return report, err
+	}
+	known := map[uint]bool{}
+	var versions []uint
+	for _, m := range d.list() {
+		if m.Direction == source.Up {
+			known[m.Version] = true
+			versions = append(versions, m.Version)
+		}
+	}
+
+	done := map[uint]bool{}
+	var latest uint
+	for _, v := range applied {
+		if done[v] {
+			continue
+		}
+		done[v] = true
+		if v > latest {
+			latest = v
+		}
+		if !known[v] {
+			report.Missing = append(report.Missing, v)
+		}
+	}
+	sort.Slice(report.Missing, func(i, j int) bool { return report.Missing[i] < report.Missing[j] })
+
+	for _, v := range versions {
+		switch {
+		case done[v]:
+		case len(done) > 0 && v < latest:
+			report.Skipped = append(report.Skipped, v)
+		default:
+			report.Pending = append(report.Pending, v)
+		}
+	}
+	return report, nil
+}
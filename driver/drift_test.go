@@ -0,0 +1,41 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":       "",
+		"1_init.down.sql":     "",
+		"2_users.up.sql":      "",
+		"3_orders.up.sql":     "",
+		"4_items.up.sql":      "",
+		"5_cleanup.up.sql":    "",
+		"6_rollback.down.sql": "",
+	})
+	d, err := WithInstance(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		applied []uint
+		want    DriftReport
+		drifted bool
+	}{
+		{nil, DriftReport{Pending: []uint{1, 2, 3, 4, 5}}, false},
+		{[]uint{2, 1}, DriftReport{Pending: []uint{3, 4, 5}}, false},
+		{[]uint{1, 2, 4}, DriftReport{Skipped: []uint{3}, Pending: []uint{5}}, true},
+		{[]uint{1, 2, 3, 4, 5, 9, 7, 7}, DriftReport{Missing: []uint{7, 9}}, true},
+	} {
+		report, err := d.Verify(test.applied)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(report, test.want) || report.Drifted() != test.drifted {
+			t.Errorf("%v: expected %+v (drifted %v), got %+v", test.applied, test.want, test.drifted, report)
+		}
+	}
+}
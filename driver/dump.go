@@ -0,0 +1,129 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// ReadUpAll returns every up migration concatenated in version order,
+// as a script that creates the schema from scratch. It is
+// ReadUpBetween over all versions.
+func (d *Driver) ReadUpAll() (io.ReadCloser, error) {
+	return d.ReadUpBetween(0, ^uint(0))
+}
+
+// ReadUpBetween returns the up migrations with versions from from to to,
+// both included, concatenated in version order. Each body is preceded by
+// a comment naming its file, like "-- 1_init.up.sql", and ends with a
+// newline. Bodies are transformed as by ReadUp, one at a time while the
+// returned reader is read, so it must be closed, but they aren't
+// reported as reads to Stats, Events or the Tracer. If there are no such
+// migrations the reader is empty.
+func (d *Driver) ReadUpBetween(from, to uint) (io.ReadCloser, error) {
+	if err := d.alive(); err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid version range %d to %d", from, to)
+	}
+	var migs []*source.Migration
+	for _, m := range d.list() {
+		if m.Direction == source.Up && from <= m.Version && m.Version <= to {
+			migs = append(migs, m)
+		}
+	}
+	return &dumpReader{read: d.body, migs: migs}, nil
+}
+
+// dumpReader reads the bodies of migs one after another through read,
+// each after its header comment.
+type dumpReader struct {
+	read   func(m *source.Migration) (io.ReadCloser, error)
+	migs   []*source.Migration
+	header io.Reader
+	body   io.ReadCloser
+	// last is the last byte of the bodies read, zero before the first.
+	last byte
+	err  error
+}
+
+func (r *dumpReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if r.header != nil {
+			n, err := r.header.Read(p)
+			if err == io.EOF {
+				r.header, err = nil, nil
+			}
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		if r.body != nil {
+			n, err := r.body.Read(p)
+			if n > 0 {
+				r.last = p[n-1]
+			}
+			if err == io.EOF {
+				err = r.next()
+			}
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		if len(r.migs) == 0 {
+			return 0, io.EOF
+		}
+		m := r.migs[0]
+		r.migs = r.migs[1:]
+		body, err := r.read(m)
+		if err != nil {
+			r.err = err
+			break
+		}
+		r.header = strings.NewReader(dumpHeader(m.Raw, r.last == 0))
+		r.body = body
+		r.last = '\n'
+	}
+	return 0, r.err
+}
+
+// next closes the current body, ending it with a newline if needed.
+func (r *dumpReader) next() error {
+	err := r.body.Close()
+	r.body = nil
+	if err != nil {
+		r.err = err
+		return err
+	}
+	if r.last != '\n' {
+		r.header = strings.NewReader("\n")
+	}
+	return nil
+}
+
+// dumpHeader returns the comment preceding the body of raw, set apart from
+// the previous body unless it is the first.
+func dumpHeader(raw string, first bool) string {
+	if first {
+		return "-- " + raw + "\n"
+	}
+	return "\n-- " + raw + "\n"
+}
+
+func (r *dumpReader) Close() error {
+	r.migs = nil
+	if r.err == nil {
+		r.err = fmt.Errorf("read of closed migrations")
+	}
+	if r.body != nil {
+		err := r.body.Close()
+		r.body = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package driver
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestReadUpAll(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":    "CREATE TABLE a();",
+		"1_init.down.sql":  "DROP TABLE a;",
+		"2_empty.up.sql":   "",
+		"3_users.up.sql":   "CREATE TABLE users();\n",
+		"4_cleanup.up.sql": "DELETE;",
+	})
+	d, err := WithInstance(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		from, to uint
+		want     string
+	}{
+		{0, ^uint(0), "-- 1_init.up.sql\nCREATE TABLE a();\n" +
+			"\n-- 2_empty.up.sql\n" +
+			"\n-- 3_users.up.sql\nCREATE TABLE users();\n" +
+			"\n-- 4_cleanup.up.sql\nDELETE;\n"},
+		{2, 3, "-- 2_empty.up.sql\n\n-- 3_users.up.sql\nCREATE TABLE users();\n"},
+		{5, 9, ""},
+	} {
+		r, err := d.ReadUpBetween(test.from, test.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil || string(got) != test.want {
+			t.Errorf("%d to %d: expected %q, got %q, %v", test.from, test.to, test.want, got, err)
+		}
+	}
+
+	r, err := d.ReadUpAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, err := d.ReadUpBetween(3, 2); err == nil {
+		t.Error("expected an error for an inverted range")
+	}
+}
+
+func TestReadUpAllReleasesReaders(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_a.up.sql": "a",
+		"2_b.up.sql": "b",
+		"3_c.up.sql": "c",
+	})
+	d, err := WithInstance(box, WithReadConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := d.ReadUpAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if body, err := readUp(d)(2); err != nil || body != "b" {
+		t.Errorf("expected the reader slots to be released, got %q, %v", body, err)
+	}
+}
@@ -0,0 +1,56 @@
+package driver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"golang.org/x/text/encoding"
+)
+
+// sniffLen is the number of bytes handed to an encoding sniffer.
+const sniffLen = 512
+
+// WithEncodingSniffer installs a function that inspects the first bytes
+// of every migration body returned by ReadUp and ReadDown and decides how
+// it is encoded, as an encoding from golang.org/x/text, such as
+//
+//	return charmap.Windows1252, nil
+//
+// The body is transcoded from it to UTF-8. A nil encoding means the body
+// is already UTF-8, which is what the driver assumes when no sniffer is
+// configured. Sniffing only peeks at the body; the returned reader
+// starts at the first byte.
+func WithEncodingSniffer(sniff func(head []byte) (encoding.Encoding, error)) Option {
+	return func(d *Driver) {
+		d.sniff = sniff
+	}
+}
+
+// decode runs the encoding sniffer on r and transcodes it if required.
+func (d *Driver) decode(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error) {
+	if d.sniff == nil {
+		return r, nil
+	}
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	enc, err := d.sniff(head)
+	if err != nil {
+		return nil, fmt.Errorf("unable to detect encoding: %v", err)
+	}
+	if enc == nil {
+		return readCloser{Reader: br, Closer: r}, nil
+	}
+	return readCloser{Reader: enc.NewDecoder().Reader(br), Closer: r}, nil
+}
+
+// readCloser combines a reader with the closer of the
+// body it was derived from.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
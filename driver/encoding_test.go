@@ -0,0 +1,82 @@
+package driver
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestWithEncodingSniffer(t *testing.T) {
+	var seen []byte
+	sniff := func(head []byte) (encoding.Encoding, error) {
+		seen = append([]byte(nil), head...)
+		if bytes.HasPrefix(head, []byte("-- latin1")) {
+			return charmap.ISO8859_1, nil
+		}
+		return nil, nil
+	}
+	d := newTestDriver(t, map[string]string{
+		"1_a.up.sql":   "-- latin1\ncaf\xe9",
+		"1_a.down.sql": "plain",
+	}, WithEncodingSniffer(sniff))
+
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := ioutil.ReadAll(r)
+	r.Close()
+	if string(got) != "-- latin1\ncafé" {
+		t.Errorf("expected transcoded body, got %q", got)
+	}
+
+	r, _, err = d.ReadDown(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = ioutil.ReadAll(r)
+	r.Close()
+	if string(got) != "plain" {
+		t.Errorf("expected untouched body, got %q", got)
+	}
+	if string(seen) != "plain" {
+		t.Errorf("expected sniffer to see the whole short body, got %q", seen)
+	}
+}
+
+func TestWithEncodingSnifferLargeBody(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 4*sniffLen)
+	var headLen int
+	sniff := func(head []byte) (encoding.Encoding, error) {
+		headLen = len(head)
+		return nil, nil
+	}
+	d := newTestDriver(t, map[string]string{"1_a.up.sql": string(body)}, WithEncodingSniffer(sniff))
+
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, _ := ioutil.ReadAll(r)
+	if !bytes.Equal(got, body) {
+		t.Errorf("sniffing consumed bytes: got %d bytes, want %d", len(got), len(body))
+	}
+	if headLen != sniffLen {
+		t.Errorf("expected sniffer to see %d bytes, got %d", sniffLen, headLen)
+	}
+}
+
+func TestWithEncodingSnifferError(t *testing.T) {
+	sniff := func(head []byte) (encoding.Encoding, error) {
+		return nil, fmt.Errorf("unsupported")
+	}
+	d := newTestDriver(t, map[string]string{"1_a.up.sql": "x"}, WithEncodingSniffer(sniff))
+	if _, _, err := d.ReadUp(1); err == nil {
+		t.Fatal("expected sniffer error")
+	}
+}
@@ -0,0 +1,127 @@
+package driver
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// encryptedExt is the extension of encrypted files.
+const encryptedExt = ".enc"
+
+// WithDecryptionKey makes ReadUp and ReadDown decrypt files with the
+// extension .enc, like 5_seed.up.sql.enc, using AES-GCM with key, which
+// has 16, 24 or 32 bytes. The files hold a random nonce followed by the
+// sealed body, as written by Encrypt, and may be compressed before they
+// were encrypted, like 5_seed.up.sql.gz.enc. Only the ciphertext is
+// compiled into the binary; manifests and signatures cover it as stored.
+// Without a key, the driver fails to open if there are encrypted files.
+func WithDecryptionKey(key []byte) Option {
+	return func(d *Driver) {
+		d.decryptionKey = key
+		d.keyErr = nil
+	}
+}
+
+// WithDecryptionKeyEnv is like WithDecryptionKey with the base64 encoded
+// key read from the environment variable name when the option is
+// applied, so the key can be injected at deploy time.
+func WithDecryptionKeyEnv(name string) Option {
+	return func(d *Driver) {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			d.decryptionKey, d.keyErr = nil, fmt.Errorf("decryption key variable %s not set", name)
+			return
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+		if err != nil {
+			d.decryptionKey, d.keyErr = nil, fmt.Errorf("invalid decryption key in %s: %v", name, err)
+			return
+		}
+		d.decryptionKey, d.keyErr = key, nil
+	}
+}
+
+// Encrypt seals body with AES-GCM using key, in the format read from
+// .enc files by drivers created with WithDecryptionKey.
+func Encrypt(key, body []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, body, nil), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// isEncrypted reports whether raw is the name of an encrypted file.
+func isEncrypted(raw string) bool {
+	return path.Ext(raw) == encryptedExt
+}
+
+// checkEncryption checks that encrypted files can be decrypted with
+// the configured key.
+func (d *Driver) checkEncryption(idx *index) error {
+	if d.keyErr != nil {
+		return d.keyErr
+	}
+	if d.decryptionKey != nil {
+		if _, err := newAEAD(d.decryptionKey); err != nil {
+			return fmt.Errorf("invalid decryption key: %v", err)
+		}
+		return nil
+	}
+	var encrypted []string
+	for _, m := range idx.list() {
+		if isEncrypted(m.Raw) {
+			encrypted = append(encrypted, m.Raw)
+		}
+	}
+	if len(encrypted) == 0 {
+		return nil
+	}
+	sort.Strings(encrypted)
+	return fmt.Errorf("encrypted migrations without a decryption key: %s", strings.Join(encrypted, ", "))
+}
+
+// decrypt replaces the body of an encrypted file with its plaintext.
+func (d *Driver) decrypt(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error) {
+	if !isEncrypted(m.Raw) {
+		return r, nil
+	}
+	aead, err := newAEAD(d.decryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt migration %s: %v", m.Raw, err)
+	}
+	data, err := d.readAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("unable to decrypt migration %s: file too short", m.Raw)
+	}
+	body, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt migration %s: %v", m.Raw, err)
+	}
+	return readCloser{Reader: d.buffered(body), Closer: r}, nil
+}
@@ -0,0 +1,82 @@
+package driver
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func encrypted(t *testing.T, key []byte, s string) string {
+	t.Helper()
+	data, err := Encrypt(key, []byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestDecrypt(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":         "CREATE TABLE a();",
+		"2_seed.up.sql.enc":     encrypted(t, testKey, "INSERT INTO keys VALUES ('secret');"),
+		"3_seed.up.sql.gz.enc":  encrypted(t, testKey, gzipped(t, "INSERT INTO endpoints;")),
+		"4_corrupt.up.sql.enc":  "short",
+		"5_tampered.up.sql.enc": encrypted(t, []byte("fedcba9876543210fedcba9876543210"), "x"),
+	}
+	d, err := WithInstance(newTestBox(files), WithDecryptionKey(testKey), WithStrictParsing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := readUp(d)
+	for version, want := range map[uint]string{
+		1: "CREATE TABLE a();",
+		2: "INSERT INTO keys VALUES ('secret');",
+		3: "INSERT INTO endpoints;",
+	} {
+		body, err := read(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != want {
+			t.Errorf("expected %q for version %d, got %q", want, version, body)
+		}
+	}
+	for _, version := range []uint{4, 5} {
+		if _, err := read(version); err == nil || !strings.Contains(err.Error(), "unable to decrypt") {
+			t.Errorf("expected decryption error for version %d, got %v", version, err)
+		}
+	}
+
+	if _, err := WithInstance(newTestBox(files)); err == nil || !strings.Contains(err.Error(), "2_seed.up.sql.enc") {
+		t.Errorf("expected error naming the encrypted files without a key, got %v", err)
+	}
+	if _, err := WithInstance(newTestBox(files), WithDecryptionKey([]byte("short"))); err == nil {
+		t.Error("expected error for invalid key")
+	}
+}
+
+func TestDecryptionKeyEnv(t *testing.T) {
+	files := map[string]string{"1_seed.up.sql.enc": encrypted(t, testKey, "INSERT;")}
+	const name = "PACKR_TEST_DECRYPTION_KEY"
+	os.Unsetenv(name)
+	if _, err := WithInstance(newTestBox(files), WithDecryptionKeyEnv(name)); err == nil {
+		t.Error("expected error for unset key variable")
+	}
+
+	os.Setenv(name, base64.StdEncoding.EncodeToString(testKey))
+	defer os.Unsetenv(name)
+	opts, err := queryOptions(map[string][]string{"keyenv": {name}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := WithInstance(newTestBox(files), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(1); err != nil || body != "INSERT;" {
+		t.Errorf("expected decrypted body, got %q, %v", body, err)
+	}
+}
@@ -0,0 +1,62 @@
+package driver
+
+import (
+	"path"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// envDir is the directory below the migration directory holding the
+// directories of environment specific migrations.
+const envDir = "env"
+
+// WithEnvironment makes the driver serve the migrations common to all
+// environments along with those of the environment name, leaving out
+// the ones of other environments. A migration belongs to an environment
+// if the last dot separated part of its identifier names it, like
+// 0007_add_index.prod.up.sql, which is served as add_index, or if its
+// file lies in the directory of the environment below env, like
+// env/staging/0008_fixtures.up.sql. Identifiers of common migrations
+// must not contain dots then.
+//
+// Without an environment, environment tags are part of the identifier
+// and the files below env aren't migrations.
+func WithEnvironment(name string) Option {
+	return func(d *Driver) {
+		d.env = name
+	}
+}
+
+// parseScoped is parseName for a driver with an environment.
+func (d *Driver) parseScoped(box Box, root, file string) parsed {
+	dir, name := "", file
+	if rel, ok := inCleanDir(file, envDir); ok {
+		i := strings.IndexByte(rel, '/')
+		if i < 0 {
+			return parsed{skip: "not in an environment directory"}
+		}
+		if env := rel[:i]; env != d.env {
+			return parsed{skip: "in environment " + env}
+		}
+		dir, name = path.Join(envDir, rel[:i]), rel[i+1:]
+		if strings.Contains(name, "/") {
+			return parsed{skip: "in a subdirectory"}
+		}
+	}
+	p := d.parseName(box, path.Join(root, dir), name)
+	migs := p.migs
+	if p.repeatable != nil {
+		migs = []*source.Migration{p.repeatable}
+	}
+	for _, m := range migs {
+		if i := strings.LastIndexByte(m.Identifier, '.'); i >= 0 {
+			if env := m.Identifier[i+1:]; env != d.env {
+				return parsed{skip: "in environment " + env}
+			}
+			m.Identifier = m.Identifier[:i]
+		}
+		m.Raw = path.Join(dir, m.Raw)
+	}
+	return p
+}
@@ -0,0 +1,112 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+var envFiles = map[string]string{
+	"1_init.up.sql":               "CREATE TABLE a();",
+	"1_init.down.sql":             "DROP TABLE a;",
+	"2_add_index.prod.up.sql":     "CREATE INDEX i ON a(id);",
+	"2_add_index.prod.down.sql":   "DROP INDEX i;",
+	"3_fixtures.staging.up.sql":   "INSERT INTO a VALUES (1);",
+	"env/staging/4_more.up.sql":   "INSERT INTO a VALUES (2);",
+	"env/prod/5_partition.up.sql": "ALTER TABLE a PARTITION;",
+	"env/prod/R__views.sql":       "CREATE VIEW v;",
+	"env/6_misplaced.up.sql":      "SELECT 1;",
+	"R__reports.prod.sql":         "CREATE VIEW reports;",
+	"R__summary.staging.sql":      "CREATE VIEW summary;",
+}
+
+func TestWithEnvironment(t *testing.T) {
+	for env, want := range map[string]struct {
+		versions    []uint
+		identifiers []string
+		repeatables []string
+	}{
+		"prod":    {[]uint{1, 2, 5}, []string{"init", "add_index", "partition"}, []string{"reports", "views"}},
+		"staging": {[]uint{1, 3, 4}, []string{"init", "fixtures", "more"}, []string{"summary"}},
+		"dev":     {[]uint{1}, []string{"init"}, nil},
+	} {
+		t.Run(env, func(t *testing.T) {
+			d, err := WithInstance(newTestBox(envFiles), WithEnvironment(env))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var versions []uint
+			var identifiers []string
+			for v, err := d.First(); err == nil; v, err = d.Next(v) {
+				r, identifier, err := d.ReadUp(v)
+				if err != nil {
+					t.Fatal(err)
+				}
+				r.Close()
+				versions = append(versions, v)
+				identifiers = append(identifiers, identifier)
+			}
+			if !reflect.DeepEqual(versions, want.versions) || !reflect.DeepEqual(identifiers, want.identifiers) {
+				t.Errorf("expected %v %v, got %v %v", want.versions, want.identifiers, versions, identifiers)
+			}
+			repeatables, err := d.Repeatables()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, m := range repeatables {
+				names = append(names, m.Name)
+			}
+			if !reflect.DeepEqual(names, want.repeatables) {
+				t.Errorf("expected repeatables %v, got %v", want.repeatables, names)
+			}
+		})
+	}
+}
+
+func TestWithEnvironmentReport(t *testing.T) {
+	d, err := WithInstance(newTestBox(envFiles), WithEnvironment("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(5); err != nil || body != "ALTER TABLE a PARTITION;" {
+		t.Errorf("expected the body in the environment directory, got %q, %v", body, err)
+	}
+	skipped := map[string]string{}
+	for _, s := range d.Report().Skipped {
+		skipped[s.File] = s.Reason
+	}
+	want := map[string]string{
+		"3_fixtures.staging.up.sql": "in environment staging",
+		"env/staging/4_more.up.sql": "in environment staging",
+		"env/6_misplaced.up.sql":    "not in an environment directory",
+		"R__summary.staging.sql":    "in environment staging",
+	}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("expected skipped files %v, got %v", want, skipped)
+	}
+}
+
+func TestWithoutEnvironment(t *testing.T) {
+	d, err := WithInstance(newTestBox(envFiles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, identifier, err := d.ReadUp(2); err != nil || identifier != "add_index.prod" {
+		t.Errorf("expected the tag to be part of the identifier, got %q, %v", identifier, err)
+	}
+	if _, _, err := d.ReadUp(5); err == nil {
+		t.Error("expected files in environment directories to be ignored")
+	}
+}
+
+func TestEnvironmentURL(t *testing.T) {
+	opts, err := queryOptions(map[string][]string{"env": {"prod"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Driver{}
+	d.apply(opts)
+	if d.env != "prod" {
+		t.Errorf("expected environment prod, got %q", d.env)
+	}
+}
@@ -0,0 +1,74 @@
+package driver
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// ErrNoBox indicates that a source is not a packr box instance.
+var ErrNoBox = fmt.Errorf("not a box")
+
+// ErrNoMigrations is returned by First and PeekLatestVersion when there
+// are no migrations. It matches os.ErrNotExist, which golang-migrate
+// expects in that case.
+var ErrNoMigrations = fmt.Errorf("no migrations: %w", os.ErrNotExist)
+
+// ErrClosed is returned by the methods of a driver that was closed.
+var ErrClosed = fmt.Errorf("driver closed")
+
+// ErrDuplicateVersion reports migrations with the same version and direction.
+type ErrDuplicateVersion struct {
+	Version   uint
+	Direction source.Direction
+	// Files are the conflicting files, the one indexed first at the front.
+	Files []string
+}
+
+func (e *ErrDuplicateVersion) Error() string {
+	return fmt.Sprintf("%d %s (%s)", e.Version, e.Direction, strings.Join(e.Files, " and "))
+}
+
+// ErrIdentifierMismatch reports an up and a down migration with the same
+// version but different identifiers, like 1_init.up.sql and
+// 1_users.down.sql left behind by a bad merge.
+type ErrIdentifierMismatch struct {
+	Version uint
+	// Files are the paths of the up and the down migration.
+	Files []string
+}
+
+func (e *ErrIdentifierMismatch) Error() string {
+	return fmt.Sprintf("%d (%s)", e.Version, strings.Join(e.Files, " and "))
+}
+
+// ErrOpenFailed reports a migration file that couldn't be opened.
+type ErrOpenFailed struct {
+	File string
+	Err  error
+}
+
+func (e *ErrOpenFailed) Error() string {
+	return fmt.Sprintf("unable to open migration %s: %v", e.File, e.Err)
+}
+
+func (e *ErrOpenFailed) Unwrap() error {
+	return e.Err
+}
+
+// wrapped is an error with its own message that still unwraps to a cause,
+// used when an error summarizes several others.
+type wrapped struct {
+	msg string
+	err error
+}
+
+func (e *wrapped) Error() string {
+	return e.msg
+}
+
+func (e *wrapped) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,68 @@
+package driver
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestErrDuplicateVersion(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_a.up.sql": "",
+		"1_b.up.sql": "",
+		"2_a.up.sql": "",
+		"2_b.up.sql": "",
+	})
+	_, err := WithInstance(box)
+	var dup *ErrDuplicateVersion
+	if !errors.As(err, &dup) {
+		t.Fatalf("expected *ErrDuplicateVersion, got %v", err)
+	}
+	want := &ErrDuplicateVersion{Version: 1, Direction: source.Up, Files: []string{"1_a.up.sql", "1_b.up.sql"}}
+	if !reflect.DeepEqual(dup, want) {
+		t.Errorf("expected %+v, got %+v", want, dup)
+	}
+	if msg := "conflicting migrations: 1 up (1_a.up.sql and 1_b.up.sql), 2 up (2_a.up.sql and 2_b.up.sql)"; err.Error() != msg {
+		t.Errorf("expected %q, got %q", msg, err)
+	}
+}
+
+func TestErrOpenFailed(t *testing.T) {
+	migs := []source.Migration{{Version: 1, Identifier: "a", Direction: source.Up, Raw: "1_a.up.sql"}}
+	opener := func(raw string) (io.ReadCloser, error) { return nil, os.ErrPermission }
+	d, err := WithMigrations(migs, opener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = d.ReadUp(1)
+	var open *ErrOpenFailed
+	if !errors.As(err, &open) || open.File != "1_a.up.sql" {
+		t.Fatalf("expected *ErrOpenFailed for 1_a.up.sql, got %v", err)
+	}
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("expected the cause to be wrapped, got %v", err)
+	}
+}
+
+func TestErrNoMigrations(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{"README.md": ""}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = d.First()
+	if err != ErrNoMigrations || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNoMigrations matching os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestStrictParseErrorCause(t *testing.T) {
+	box := newTestBox(map[string]string{"1_a.up.sql": "", "notes.sql": ""})
+	_, err := WithInstance(box, WithStrictParsing())
+	if !errors.Is(err, source.ErrParse) {
+		t.Errorf("expected the parse error to be wrapped, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Events receives the events of a driver, for example to keep an audit
+// trail of the migration bodies served during a deployment. Its methods
+// are called synchronously, so they should return quickly.
+type Events interface {
+	// OnPrepare is called every time the index is built.
+	OnPrepare(e PrepareEvent)
+	// OnRead is called for every body served.
+	OnRead(e ReadEvent)
+	// OnSkip is called for every file left out of the index.
+	OnSkip(e SkipEvent)
+}
+
+// PrepareEvent describes a newly built index.
+type PrepareEvent struct {
+	Time time.Time
+	// Checksums has the checksum of every indexed file.
+	Checksums Manifest
+}
+
+// ReadEvent describes a served migration body.
+type ReadEvent struct {
+	Time       time.Time
+	Version    uint
+	Direction  source.Direction
+	Identifier string
+	File       string
+	// Checksum is the hex encoded SHA-256 checksum of the stored file
+	// when the index was built, as in the PrepareEvent, or empty if the
+	// file couldn't be read.
+	Checksum string
+}
+
+// SkipEvent describes a file left out of the index.
+type SkipEvent struct {
+	Time   time.Time
+	File   string
+	Reason string
+}
+
+// WithEvents makes the driver report its events to e. The checksums
+// in the events are computed by reading the stored files once more
+// whenever the index is built, not on every read.
+func WithEvents(e Events) Option {
+	return func(d *Driver) {
+		d.events = e
+	}
+}
+
+// eventSums returns the checksums of the files of the index migs,
+// opened by open, to report in events, or nil without WithEvents.
+func (d *Driver) eventSums(migs *migrations, open func(raw string) (io.ReadCloser, error)) Manifest {
+	if d.events == nil {
+		return nil
+	}
+	files := distinct(raws(migs))
+	list := make([]string, len(files))
+	d.parallel(len(files), func(i int) {
+		list[i], _ = checksum(open, files[i])
+	})
+	sums := make(Manifest, len(files))
+	for i, raw := range files {
+		sums[raw] = list[i]
+	}
+	return sums
+}
+
+// prepareEvent reports a newly built index with the checksums sums.
+func (d *Driver) prepareEvent(sums Manifest) {
+	if d.events != nil {
+		d.events.OnPrepare(PrepareEvent{Time: time.Now(), Checksums: sums})
+	}
+}
+
+// readEvent reports the body served for m.
+func (d *Driver) readEvent(m *source.Migration) {
+	if d.events == nil {
+		return
+	}
+	d.mu.RLock()
+	sum := d.sums[m.Raw]
+	d.mu.RUnlock()
+	d.events.OnRead(ReadEvent{
+		Time:       time.Now(),
+		Version:    m.Version,
+		Direction:  m.Direction,
+		Identifier: m.Identifier,
+		File:       m.Raw,
+		Checksum:   sum,
+	})
+}
+
+// skipEvent reports a file left out of the index.
+func (d *Driver) skipEvent(file string, reason interface{}) {
+	if d.events != nil {
+		d.events.OnSkip(SkipEvent{Time: time.Now(), File: file, Reason: fmt.Sprint(reason)})
+	}
+}
@@ -0,0 +1,107 @@
+package driver
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+type recordingEvents struct {
+	prepares []PrepareEvent
+	reads    []ReadEvent
+	skips    []SkipEvent
+}
+
+func (e *recordingEvents) OnPrepare(ev PrepareEvent) { e.prepares = append(e.prepares, ev) }
+func (e *recordingEvents) OnRead(ev ReadEvent)       { e.reads = append(e.reads, ev) }
+func (e *recordingEvents) OnSkip(ev SkipEvent)       { e.skips = append(e.skips, ev) }
+
+func TestWithEvents(t *testing.T) {
+	events := &recordingEvents{}
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":   "1 up",
+		"1_init.down.sql": "1 down",
+		"README.md":       "",
+	})
+	before := time.Now()
+	d, err := WithInstance(box, WithEvents(events))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readDown(d)(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events.skips) != 1 || events.skips[0].File != "README.md" || events.skips[0].Reason != "extension not allowed" {
+		t.Errorf("unexpected skip events %+v", events.skips)
+	}
+	if len(events.prepares) != 1 {
+		t.Fatalf("expected one prepare event, got %+v", events.prepares)
+	}
+	want := Manifest{"1_init.up.sql": sha("1 up"), "1_init.down.sql": sha("1 down")}
+	if got := events.prepares[0].Checksums; len(got) != 2 || got["1_init.up.sql"] != want["1_init.up.sql"] || got["1_init.down.sql"] != want["1_init.down.sql"] {
+		t.Errorf("expected checksums %v, got %v", want, got)
+	}
+	if len(events.reads) != 1 {
+		t.Fatalf("expected one read event, got %+v", events.reads)
+	}
+	read := events.reads[0]
+	if read.Version != 1 || read.Direction != source.Down || read.Identifier != "init" || read.File != "1_init.down.sql" || read.Checksum != sha("1 down") {
+		t.Errorf("unexpected read event %+v", read)
+	}
+	if read.Time.Before(before) {
+		t.Errorf("expected a timestamp, got %v", read.Time)
+	}
+}
+
+func TestInternalReadsAreNotReported(t *testing.T) {
+	events := &recordingEvents{}
+	d, err := WithInstance(newTestBox(map[string]string{
+		"1_init.up.sql":   "1 up",
+		"1_init.down.sql": "1 down",
+	}), WithEvents(events))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Lint(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := d.ReadUpAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if len(events.reads) != 0 {
+		t.Errorf("expected no read events, got %v", events.reads)
+	}
+	if s := d.Stats(); s.UpReads != 0 || s.DownReads != 0 || s.ReadBytes != 0 {
+		t.Errorf("expected no reads in the stats, got %+v", s)
+	}
+}
+
+func TestReadEventReusesChecksum(t *testing.T) {
+	events := &recordingEvents{}
+	reads := 0
+	d, err := WithAssetFunc(func() []string { return []string{"1_init.up.sql"} }, func(name string) ([]byte, error) {
+		reads++
+		return []byte("1 up"), nil
+	}, WithEvents(events))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := reads
+	if body, err := readUp(d)(1); err != nil || body != "1 up" {
+		t.Fatalf("expected the body, got %q, %v", body, err)
+	}
+	if reads != before+1 {
+		t.Errorf("expected the file to be read once for the body, got %d reads", reads-before)
+	}
+	if len(events.reads) != 1 || events.reads[0].Checksum != sha("1 up") {
+		t.Errorf("expected a read event with the checksum of the file, got %+v", events.reads)
+	}
+}
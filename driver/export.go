@@ -0,0 +1,57 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Export writes every migration file known to the driver to dir,
+// which is created if needed. Files keep their names and are written
+// as stored, without decompressing, decoding or rendering them, so
+// they can be reviewed with other tools or checked against a manifest.
+// Existing files in dir are overwritten.
+func (d *Driver) Export(dir string) error {
+	if err := d.alive(); err != nil {
+		return err
+	}
+	d.mu.RLock()
+	open := d.open
+	d.mu.RUnlock()
+
+	done := map[string]bool{}
+	for _, m := range d.list() {
+		if done[m.Raw] {
+			continue
+		}
+		done[m.Raw] = true
+		if err := export(open, m.Raw, dir); err != nil {
+			return fmt.Errorf("unable to export migration %s: %v", m.Raw, err)
+		}
+	}
+	return nil
+}
+
+// export copies the file stored at raw to the same name in dir.
+func export(open func(raw string) (io.ReadCloser, error), raw, dir string) error {
+	name := filepath.Join(dir, filepath.FromSlash(cleanPath(raw)))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	r, err := open(raw)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
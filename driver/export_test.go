@@ -0,0 +1,73 @@
+package driver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"db/1_init.up.sql":   "init up",
+		"db/1_init.down.sql": "init down",
+		"db/2_users.up.sql":  "users up",
+		"db/README.md":       "not a migration",
+		"other/3_x.up.sql":   "outside the root",
+	})
+	d, err := WithInstance(box, WithRoot("db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "exported")
+	if err := d.Export(out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"1_init.up.sql":   "init up",
+		"1_init.down.sql": "init down",
+		"2_users.up.sql":  "users up",
+	}
+	files, err := ioutil.ReadDir(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(want) {
+		t.Errorf("expected %d files, got %d", len(want), len(files))
+	}
+	for name, body := range want {
+		data, err := ioutil.ReadFile(filepath.Join(out, name))
+		if err != nil || string(data) != body {
+			t.Errorf("%s: expected %q, got %q, %v", name, body, data, err)
+		}
+	}
+}
+
+func TestExportGoose(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.sql": "-- +goose Up\ncreate;\n-- +goose Down\ndrop;\n",
+	})
+	d, err := WithInstance(box, WithGooseFormat())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := d.Export(dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "1_init.sql"))
+	if err != nil || string(data) != "-- +goose Up\ncreate;\n-- +goose Down\ndrop;\n" {
+		t.Errorf("expected the stored file, got %q, %v", data, err)
+	}
+}
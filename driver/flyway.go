@@ -0,0 +1,58 @@
+package driver
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// flywayRegex matches Flyway file names: V1__desc.sql for versioned
+// migrations, U1__desc.sql for their undo migrations and R__desc.sql
+// for repeatable migrations.
+var flywayRegex = regexp.MustCompile(`^([VUR])([0-9]+(?:[._][0-9]+)*)?__(.+)\.([^.]+)$`)
+
+// WithFlywayNaming indexes files following the Flyway naming convention
+// instead of the golang-migrate one. Versioned migrations (V1__desc.sql)
+// are up migrations and undo migrations (U1__desc.sql) are down
+// migrations of the same version. Repeatable migrations (R__desc.sql)
+// are not versioned and are served by Repeatables instead, whatever the
+// naming convention.
+//
+// Flyway versions with several parts, like V1.2 or V1_2, can only be
+// indexed with a version encoder, which receives them with dots
+// as separators ("1.2").
+func WithFlywayNaming() Option {
+	return func(d *Driver) {
+		d.parser = d.parseFlyway
+	}
+}
+
+// parseFlyway returns the migration described by the Flyway file name raw.
+func (d *Driver) parseFlyway(raw string) (*source.Migration, error) {
+	m := flywayRegex.FindStringSubmatch(raw)
+	if m == nil {
+		return nil, source.ErrParse
+	}
+	prefix, version, description := m[1], strings.Replace(m[2], "_", ".", -1), m[3]
+	// Repeatable migrations are picked out before parsing.
+	if prefix == "R" || version == "" {
+		return nil, source.ErrParse
+	}
+
+	v, err := d.version(version)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := source.Up
+	if prefix == "U" {
+		dir = source.Down
+	}
+	return &source.Migration{
+		Version:    v,
+		Identifier: description,
+		Direction:  dir,
+		Raw:        raw,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package driver
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithFlywayNaming(t *testing.T) {
+	files := map[string]string{
+		"V1__init.sql":         "init",
+		"U1__init.sql":         "undo init",
+		"V2__add_users.sql":    "users",
+		"R__refresh_views.sql": "views",
+	}
+	d, err := WithInstance(newTestBox(files), WithFlywayNaming(), WithStrictParsing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, identifier, err := d.ReadDown(1); err != nil || identifier != "init" {
+		t.Errorf("expected undo migration init, got %q, %v", identifier, err)
+	}
+	if _, identifier, err := d.ReadUp(2); err != nil || identifier != "add_users" {
+		t.Errorf("expected add_users, got %q, %v", identifier, err)
+	}
+	if _, err := d.Next(2); err == nil {
+		t.Error("expected repeatable migration to be left out of the index")
+	}
+
+	files["1_golang_migrate.up.sql"] = ""
+	if _, err := WithInstance(newTestBox(files), WithFlywayNaming(), WithStrictParsing()); err == nil {
+		t.Error("expected strict mode to reject the golang-migrate style file")
+	}
+}
+
+func TestWithFlywayNamingDottedVersions(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"V1.2__a.sql": "",
+		"V1_3__b.sql": "",
+	})
+	if _, err := WithInstance(box, WithFlywayNaming(), WithStrictParsing()); err == nil {
+		t.Fatal("expected dotted versions to need an encoder")
+	}
+
+	encode := func(raw string) (uint, error) {
+		parts := strings.Split(raw, ".")
+		major, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return 0, err
+		}
+		minor, err := strconv.Atoi(parts[1])
+		return uint(major*1000 + minor), err
+	}
+	d, err := WithInstance(box, WithVersionEncoder(encode, nil), WithFlywayNaming())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.Next(1002); err != nil || v != 1003 {
+		t.Errorf("expected 1003 after 1002, got %d, %v", v, err)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build go1.18
+// +build go1.18
+
+package driver
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fuzzOptions returns the options selected by the bits of flags.
+func fuzzOptions(flags uint8) []Option {
+	var opts []Option
+	for bit, opt := range []Option{
+		WithStrictParsing(),
+		WithCaseInsensitiveNames(),
+		WithEnvironment("prod"),
+		WithRoot("db"),
+		WithParallelism(3),
+		WithGooseFormat(),
+		WithIdentifierCheck(),
+		WithExtensions(),
+	} {
+		if flags&(1<<bit) != 0 {
+			opts = append(opts, opt)
+		}
+	}
+	return opts
+}
+
+// FuzzIndex builds drivers from boxes holding the newline separated
+// file names of names, as a plugin could contribute them, and checks
+// the driver neither panics nor builds an inconsistent index.
+func FuzzIndex(f *testing.F) {
+	for _, seed := range []struct {
+		names string
+		flags uint8
+	}{
+		{"1_init.up.sql\n1_init.down.sql\n2_users.up.sql", 0},
+		{"db/1_init.up.sql\ndb/seeds/1_data.up.sql\ndb/R__views.sql", 1 << 3},
+		{"1_a.up.sql\n1_b.up.sql", 1},
+		{"0001_Init.UP.SQL\n1_init.up.sql\n2_x.up.sql.gz", 1 << 1},
+		{"7_index.prod.up.sql\nenv/prod/8_x.up.sql\nenv/9_y.up.sql\nenv/staging/", 1 << 2},
+		{"1_café.up.sql\n1_café.down.sql\n\\2_win.up.sql\n/3_abs.up.sql\n./4_dot.up.sql\n../5_up.up.sql", 1<<6 | 1<<4},
+		{"18446744073709551616_big.up.sql\n-1_neg.up.sql\n1__.up.sql\n1_.up.sql\n_1.up.sql", 0},
+		{"1_goose.sql\nR__.sql\n.sql\n\x00\n", 1<<5 | 1<<7},
+	} {
+		f.Add(seed.names, seed.flags)
+	}
+	f.Fuzz(func(t *testing.T, names string, flags uint8) {
+		box := memoryBox{}
+		for i, name := range strings.Split(names, "\n") {
+			box[name] = []byte(fmt.Sprintf("-- +goose Up\nSELECT %d;\n", i))
+		}
+		d, err := WithInstance(box, fuzzOptions(flags)...)
+		if err != nil {
+			return
+		}
+		checkIndex(t, d, len(box))
+	})
+}
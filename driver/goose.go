@@ -0,0 +1,112 @@
+package driver
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// gooseRegex matches the names of goose migration files, like
+// 20230101120000_add_users.sql.
+var gooseRegex = regexp.MustCompile(`^([0-9]+)_(.+)\.sql$`)
+
+// WithGooseFormat indexes single file migrations in the format of
+// goose and sql-migrate, where a file such as 1_add_users.sql holds
+// both directions in sections starting with -- +goose Up and
+// -- +goose Down (or -- +migrate Up and -- +migrate Down).
+// ReadUp and ReadDown return only the section for their direction.
+// Files without a down section have only an up migration.
+func WithGooseFormat() Option {
+	return func(d *Driver) {
+		d.goose = true
+	}
+}
+
+// parseGoose returns the migrations in the goose file raw inside
+// the directory root of box.
+func (d *Driver) parseGoose(box Box, root, raw string) ([]*source.Migration, error) {
+	match := gooseRegex.FindStringSubmatch(raw)
+	if match == nil {
+		return nil, source.ErrParse
+	}
+	version, err := d.version(match[1])
+	if err != nil {
+		return nil, err
+	}
+	data, err := box.Find(path.Join(root, raw))
+	if err != nil {
+		return nil, err
+	}
+	sections := gooseSections(data)
+	if _, ok := sections[source.Up]; !ok {
+		return nil, fmt.Errorf("no up section")
+	}
+
+	migs := []*source.Migration{{Version: version, Identifier: match[2], Direction: source.Up, Raw: raw}}
+	if _, ok := sections[source.Down]; ok {
+		migs = append(migs, &source.Migration{Version: version, Identifier: match[2], Direction: source.Down, Raw: raw})
+	}
+	return migs, nil
+}
+
+// splitGoose replaces the body of a goose file by the section for the
+// direction of m.
+func (d *Driver) splitGoose(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error) {
+	if !d.goose {
+		return r, nil
+	}
+	data, err := d.readAll(r)
+	if err != nil {
+		return nil, err
+	}
+	section, ok := gooseSections(data)[m.Direction]
+	if !ok {
+		return nil, fmt.Errorf("no %s section in %s", m.Direction, m.Raw)
+	}
+	return readCloser{Reader: d.buffered(section), Closer: r}, nil
+}
+
+// gooseSections splits a goose file into its up and down sections.
+// The annotation lines themselves are left out.
+func gooseSections(data []byte) map[source.Direction][]byte {
+	sections := map[source.Direction][]byte{}
+	var current source.Direction
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, len(data)+1)
+	for s.Scan() {
+		line := s.Text()
+		if dir, ok := gooseAnnotation(line); ok {
+			current = dir
+			if _, seen := sections[dir]; !seen {
+				sections[dir] = []byte{}
+			}
+			continue
+		}
+		if current != "" {
+			sections[current] = append(append(sections[current], line...), '\n')
+		}
+	}
+	return sections
+}
+
+// gooseAnnotation reports whether line starts a section and
+// for which direction.
+func gooseAnnotation(line string) (source.Direction, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != "--" || (fields[1] != "+goose" && fields[1] != "+migrate") {
+		return "", false
+	}
+	switch fields[2] {
+	case "Up":
+		return source.Up, true
+	case "Down":
+		return source.Down, true
+	}
+	return "", false
+}
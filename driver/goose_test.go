@@ -0,0 +1,54 @@
+package driver
+
+import (
+	"testing"
+)
+
+func TestWithGooseFormat(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_users.sql": `-- +goose Up
+CREATE TABLE users (id int);
+
+-- +goose Down
+DROP TABLE users;
+`,
+		"2_orders.sql": `-- +migrate Up
+-- +goose StatementBegin
+CREATE TABLE orders (id int);
+-- +goose StatementEnd
+`,
+		"3_broken.sql": "SELECT 1;",
+	})
+	d, err := WithInstance(box, WithGooseFormat())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		version uint
+		read    func(uint) (string, error)
+		want    string
+	}{
+		{1, readUp(d), "CREATE TABLE users (id int);\n\n"},
+		{1, readDown(d), "DROP TABLE users;\n"},
+		{2, readUp(d), "-- +goose StatementBegin\nCREATE TABLE orders (id int);\n-- +goose StatementEnd\n"},
+	} {
+		body, err := tc.read(tc.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != tc.want {
+			t.Errorf("version %d: expected %q, got %q", tc.version, tc.want, body)
+		}
+	}
+
+	if _, _, err := d.ReadDown(2); err == nil {
+		t.Error("expected no down migration for a file without down section")
+	}
+	if _, err := d.Next(2); err == nil {
+		t.Error("expected file without annotations to be skipped")
+	}
+	if _, err := WithInstance(box, WithGooseFormat(), WithStrictParsing()); err == nil {
+		t.Error("expected strict mode to reject file without annotations")
+	}
+}
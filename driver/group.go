@@ -0,0 +1,64 @@
+package driver
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// WithGroup makes the driver serve only the migrations of the group
+// name, the directory of that name inside the root. This lets one box
+// hold the migrations of several databases, like postgres/ and
+// clickhouse/, each served by its own driver. A subdirectory set with
+// WithSubdir is relative to the group directory.
+//
+// Creating the driver fails if a migration file in the root lies outside
+// of any group directory, since no driver would serve it. With
+// WithKnownGroups, the group directories must also be known ones.
+func WithGroup(name string) Option {
+	return func(d *Driver) {
+		d.group = name
+	}
+}
+
+// WithKnownGroups lists the groups of the box for WithGroup, so that
+// creating the driver also fails on a group or files in a directory
+// that isn't one of them, such as a misspelled group.
+func WithKnownGroups(names ...string) Option {
+	return func(d *Driver) {
+		d.groups = make(map[string]bool, len(names))
+		for _, name := range names {
+			d.groups[name] = true
+		}
+	}
+}
+
+// checkGroups checks that every migration file in the root of box lies
+// in a group directory, if WithGroup is used.
+func (d *Driver) checkGroups(box Box) error {
+	if d.group == "" {
+		return nil
+	}
+	if d.groups != nil && !d.groups[d.group] {
+		return fmt.Errorf("unknown migration group %s", d.group)
+	}
+	var outside []string
+	for _, file := range listDir(box, d.root) {
+		if !d.allowed(file) {
+			continue
+		}
+		group := ""
+		if i := strings.IndexByte(file, '/'); i >= 0 {
+			group = file[:i]
+		}
+		if group == "" || d.groups != nil && !d.groups[group] {
+			outside = append(outside, path.Join(d.root, file))
+		}
+	}
+	if len(outside) == 0 {
+		return nil
+	}
+	sort.Strings(outside)
+	return fmt.Errorf("migrations outside of known groups: %s", strings.Join(outside, ", "))
+}
@@ -0,0 +1,56 @@
+package driver
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestWithGroup(t *testing.T) {
+	files := map[string]string{
+		"db/postgres/1_init.up.sql":   "postgres",
+		"db/clickhouse/1_init.up.sql": "clickhouse",
+		"db/clickhouse/2_x.up.sql":    "x",
+		"db/README.md":                "not a migration",
+	}
+	for group, want := range map[string]string{"postgres": "postgres", "clickhouse": "clickhouse"} {
+		d, err := WithInstance(newTestBox(files), WithRoot("db"), WithGroup(group), WithKnownGroups("postgres", "clickhouse"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body, err := readUp(d)(1); err != nil || body != want {
+			t.Errorf("%s: expected %q, got %q, %v", group, want, body, err)
+		}
+	}
+
+	if _, err := WithInstance(newTestBox(files), WithRoot("db"), WithGroup("mysql"), WithKnownGroups("postgres", "clickhouse")); err == nil {
+		t.Error("expected an error for an unknown group")
+	}
+	if _, err := WithInstance(newTestBox(files), WithRoot("db"), WithGroup("postgres"), WithKnownGroups("postgres")); err == nil {
+		t.Error("expected an error for files of a group that isn't known")
+	}
+
+	files["db/3_stray.up.sql"] = ""
+	_, err := WithInstance(newTestBox(files), WithRoot("db"), WithGroup("postgres"))
+	if msg := "migrations outside of known groups: db/3_stray.up.sql"; err == nil || err.Error() != msg {
+		t.Errorf("expected %q, got %v", msg, err)
+	}
+}
+
+func TestOpenWithGroup(t *testing.T) {
+	dir := t.TempDir()
+	for _, group := range []string{"postgres", "clickhouse"} {
+		if err := os.Mkdir(path.Join(dir, group), 0755); err != nil {
+			t.Fatal(err)
+		}
+		mustWriteFile(t, path.Join(dir, group), "1_init.up.sql", group)
+	}
+
+	d, err := (&Driver{}).Open("packr://" + dir + "?group=clickhouse&groups=postgres,clickhouse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d.(*Driver))(1); err != nil || body != "clickhouse" {
+		t.Errorf("expected %q, got %q, %v", "clickhouse", body, err)
+	}
+}
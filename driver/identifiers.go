@@ -0,0 +1,106 @@
+package driver
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// nonIdentifier matches runs of characters not allowed in a normalized
+// identifier.
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9]+`)
+
+// NormalizeIdentifier returns s lower-cased with runs of other characters
+// than letters and digits replaced by single underscores, so
+// "Add Users" becomes add_users. Identifiers are compared in this form
+// when looking for mismatches.
+func NormalizeIdentifier(s string) string {
+	return strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(s), "_"), "_")
+}
+
+// Sanitizer checks the identifier of a migration parsed from its file
+// name and returns the identifier to serve instead. An error makes the
+// file unparseable, so it is skipped, or reported in strict mode.
+type Sanitizer func(identifier string) (string, error)
+
+// WithIdentifierSanitizer makes the driver pass the identifier of every
+// migration through s, for example to enforce naming rules with
+// SnakeCaseIdentifiers or to serve NormalizeIdentifier's form of it.
+// Repeatable migrations keep their names.
+func WithIdentifierSanitizer(s Sanitizer) Option {
+	return func(d *Driver) {
+		d.sanitize = s
+	}
+}
+
+// SnakeCaseIdentifiers returns a Sanitizer rejecting identifiers that
+// aren't in lower snake_case, like add_users, or that are longer than
+// maxLength bytes. A maxLength of zero means no limit.
+func SnakeCaseIdentifiers(maxLength int) Sanitizer {
+	return func(identifier string) (string, error) {
+		if identifier == "" || identifier != NormalizeIdentifier(identifier) {
+			return "", fmt.Errorf("identifier '%s' is not in snake_case", identifier)
+		}
+		if maxLength > 0 && len(identifier) > maxLength {
+			return "", fmt.Errorf("identifier '%s' is longer than %d characters", identifier, maxLength)
+		}
+		return identifier, nil
+	}
+}
+
+// sanitized returns the migrations with their identifiers sanitized.
+func (d *Driver) sanitized(p parsed) parsed {
+	if d.sanitize == nil || p.err != nil {
+		return p
+	}
+	for _, m := range p.migs {
+		identifier, err := d.sanitize(m.Identifier)
+		if err != nil {
+			return parsed{err: err}
+		}
+		m.Identifier = identifier
+	}
+	return p
+}
+
+// WithIdentifierCheck makes building the index fail if the up and down
+// migrations of a version have different identifiers, compared in
+// NormalizeIdentifier's form, instead of only reporting them in Lint.
+// The error lists every such version with the paths of both files,
+// including the directory of the box they were found in.
+func WithIdentifierCheck() Option {
+	return func(d *Driver) {
+		d.matchIdentifiers = true
+	}
+}
+
+// checkIdentifiers checks the identifiers of idx, see WithIdentifierCheck.
+func (d *Driver) checkIdentifiers(idx *index, owners map[string]origin) error {
+	if !d.matchIdentifiers {
+		return nil
+	}
+	var mismatches []*ErrIdentifierMismatch
+	v, ok := idx.migrations.First()
+	for ; ok; v, ok = idx.migrations.Next(v) {
+		up, hasUp := idx.migrations.Up(v)
+		down, hasDown := idx.migrations.Down(v)
+		if !hasUp || !hasDown || NormalizeIdentifier(up.Identifier) == NormalizeIdentifier(down.Identifier) {
+			continue
+		}
+		mismatches = append(mismatches, &ErrIdentifierMismatch{
+			Version: v,
+			Files:   []string{path.Join(owners[up.Raw].root, up.Raw), path.Join(owners[down.Raw].root, down.Raw)},
+		})
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(mismatches))
+	for i, m := range mismatches {
+		msgs[i] = m.Error()
+	}
+	sort.Strings(msgs)
+	return &wrapped{"migrations with the same version but different identifiers: " + strings.Join(msgs, ", "), mismatches[0]}
+}
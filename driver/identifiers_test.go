@@ -0,0 +1,116 @@
+package driver
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeIdentifier(t *testing.T) {
+	for in, want := range map[string]string{
+		"add_users":     "add_users",
+		"Add Users":     "add_users",
+		"add-users--v2": "add_users_v2",
+		"  __init__ ":   "init",
+		"!!":            "",
+	} {
+		if got := NormalizeIdentifier(in); got != want {
+			t.Errorf("NormalizeIdentifier(%q): expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestSnakeCaseIdentifiers(t *testing.T) {
+	sanitize := SnakeCaseIdentifiers(10)
+	for identifier, ok := range map[string]bool{
+		"add_users":   true,
+		"v2":          true,
+		"AddUsers":    false,
+		"add-users":   false,
+		"_add":        false,
+		"":            false,
+		"add_columns": false,
+	} {
+		got, err := sanitize(identifier)
+		if ok && (err != nil || got != identifier) {
+			t.Errorf("%q: expected it to pass, got %q, %v", identifier, got, err)
+		}
+		if !ok && err == nil {
+			t.Errorf("%q: expected an error", identifier)
+		}
+	}
+	if _, err := SnakeCaseIdentifiers(0)(strings.Repeat("a", 200)); err != nil {
+		t.Errorf("expected no length limit, got %v", err)
+	}
+}
+
+func TestWithIdentifierSanitizer(t *testing.T) {
+	files := map[string]string{
+		"1_Add Users.up.sql": "CREATE TABLE users();",
+		"2_orders.up.sql":    "CREATE TABLE orders();",
+	}
+	normalize := func(identifier string) (string, error) { return NormalizeIdentifier(identifier), nil }
+	d, err := WithInstance(newTestBox(files), WithIdentifierSanitizer(normalize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, identifier, err := d.ReadUp(1); err != nil || identifier != "add_users" {
+		t.Errorf("expected the normalized identifier, got %q, %v", identifier, err)
+	}
+
+	d, err = WithInstance(newTestBox(files), WithIdentifierSanitizer(SnakeCaseIdentifiers(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.First(); err != nil || v != 2 {
+		t.Errorf("expected the rejected migration to be skipped, got %d, %v", v, err)
+	}
+	if _, err := WithInstance(newTestBox(files), WithIdentifierSanitizer(SnakeCaseIdentifiers(0)), WithStrictParsing()); err == nil || !strings.Contains(err.Error(), "1_Add Users.up.sql (identifier 'Add Users' is not in snake_case)") {
+		t.Errorf("expected the rejected file in strict mode, got %v", err)
+	}
+}
+
+func TestWithIdentifierCheck(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"db/a/1_init.up.sql":   "CREATE TABLE a();",
+		"db/a/2_Users.up.sql":  "CREATE TABLE users();",
+		"db/a/3_orders.up.sql": "CREATE TABLE orders();",
+	})
+	merged := newTestBox(map[string]string{
+		"1_users.down.sql":  "DROP TABLE users;",
+		"2_users.down.sql":  "DROP TABLE users;",
+		"3_totals.down.sql": "DROP TABLE totals;",
+	})
+	if _, err := WithInstance(box, WithRoot("db/a"), WithBoxes(merged)); err != nil {
+		t.Fatalf("expected mismatches to be accepted without the check, got %v", err)
+	}
+
+	_, err := WithInstance(box, WithRoot("db/a"), WithBoxes(merged), WithIdentifierCheck())
+	want := "migrations with the same version but different identifiers: 1 (db/a/1_init.up.sql and 1_users.down.sql), 3 (db/a/3_orders.up.sql and 3_totals.down.sql)"
+	if err == nil || err.Error() != want {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+	var mismatch *ErrIdentifierMismatch
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected an *ErrIdentifierMismatch, got %T", err)
+	}
+	if mismatch.Version != 1 || !reflect.DeepEqual(mismatch.Files, []string{"db/a/1_init.up.sql", "1_users.down.sql"}) {
+		t.Errorf("unexpected mismatch %+v", mismatch)
+	}
+}
+
+func TestIdentifierCheckURL(t *testing.T) {
+	opts, err := queryOptions(map[string][]string{"idcheck": {"true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Driver{}
+	d.apply(opts)
+	if !d.matchIdentifiers {
+		t.Error("expected the identifier check")
+	}
+	if _, err := queryOptions(map[string][]string{"idcheck": {"maybe"}}); err == nil {
+		t.Error("expected an error for an invalid idcheck value")
+	}
+}
@@ -0,0 +1,95 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Limits bound the migrations a driver accepts, see WithLimits.
+// Zero values don't limit anything.
+type Limits struct {
+	// MaxBytes is the largest body allowed.
+	MaxBytes int64
+	// MaxStatements is the most SQL statements a body may hold.
+	MaxStatements int
+	// Forbidden are statements that may not appear in any body, given
+	// by their leading keywords, like "DROP DATABASE" or "TRUNCATE".
+	// They match case-insensitively, whatever whitespace separates the
+	// keywords.
+	Forbidden []string
+}
+
+// WithLimits makes the driver read every migration when it is created
+// and fail if one breaks the limits, naming the offending files, so a
+// dangerous or accidentally huge file is rejected before the database
+// is contacted. Bodies are checked as ReadUp and ReadDown serve them,
+// after decompression, templates and the preprocessors, and are split
+// into statements like by Statements.
+func WithLimits(l Limits) Option {
+	return func(d *Driver) {
+		d.limits = l
+		d.limits.Forbidden = make([]string, len(l.Forbidden))
+		for i, f := range l.Forbidden {
+			d.limits.Forbidden[i] = keywords(f)
+		}
+	}
+}
+
+// checkLimits checks every migration body of idx against the limits.
+func (d *Driver) checkLimits(idx *index) error {
+	l := d.limits
+	if l.MaxBytes <= 0 && l.MaxStatements <= 0 && len(l.Forbidden) == 0 {
+		return nil
+	}
+	var offending []string
+	for _, m := range idx.list() {
+		r, err := d.loadFrom(idx, m)
+		if err != nil {
+			return err
+		}
+		body, err := d.readAll(r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("unable to read migration %s: %v", m.Raw, err)
+		}
+		for _, v := range l.violations(body) {
+			offending = append(offending, fmt.Sprintf("%s (%s)", m.Raw, v))
+		}
+	}
+	if len(offending) == 0 {
+		return nil
+	}
+	sort.Strings(offending)
+	return fmt.Errorf("migrations exceeding limits: %s", strings.Join(offending, ", "))
+}
+
+// violations describes how body breaks the limits.
+func (l Limits) violations(body []byte) []string {
+	var found []string
+	if l.MaxBytes > 0 && int64(len(body)) > l.MaxBytes {
+		found = append(found, fmt.Sprintf("%d bytes, more than %d", len(body), l.MaxBytes))
+	}
+	if l.MaxStatements <= 0 && len(l.Forbidden) == 0 {
+		return found
+	}
+	statements := splitBody(string(body))
+	if l.MaxStatements > 0 && len(statements) > l.MaxStatements {
+		found = append(found, fmt.Sprintf("%d statements, more than %d", len(statements), l.MaxStatements))
+	}
+	for _, s := range statements {
+		k := keywords(stripComments(s))
+		for _, f := range l.Forbidden {
+			if k == f || strings.HasPrefix(k, f+" ") {
+				found = append(found, "forbidden statement "+f)
+			}
+		}
+	}
+	return found
+}
+
+// keywords returns s upper-cased with runs of whitespace replaced by
+// single spaces.
+func keywords(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,61 @@
+package driver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithLimits(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":     "CREATE TABLE a();\nCREATE TABLE b();",
+		"2_big.up.sql":      strings.Repeat("x", 200),
+		"3_many.up.sql":     "SELECT 1; SELECT 2; SELECT 3; SELECT 4;",
+		"4_danger.down.sql": "-- careful\ndrop\n  database   app;",
+		"5_quoted.up.sql":   "INSERT INTO notes VALUES ('DROP DATABASE app');",
+		"6_escaped.up.sql":  "INSERT INTO notes VALUES ('it\\'s; DROP DATABASE app');",
+	}
+	_, err := WithInstance(newTestBox(files), WithLimits(Limits{
+		MaxBytes:      100,
+		MaxStatements: 3,
+		Forbidden:     []string{"drop database", "TRUNCATE"},
+	}))
+	if err == nil {
+		t.Fatal("expected error for migrations exceeding limits")
+	}
+	want := "migrations exceeding limits: 2_big.up.sql (200 bytes, more than 100), " +
+		"3_many.up.sql (4 statements, more than 3), 4_danger.down.sql (forbidden statement DROP DATABASE)"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err)
+	}
+
+	delete(files, "2_big.up.sql")
+	delete(files, "3_many.up.sql")
+	delete(files, "4_danger.down.sql")
+	if _, err := WithInstance(newTestBox(files), WithLimits(Limits{MaxBytes: 100, MaxStatements: 3, Forbidden: []string{"DROP DATABASE"}})); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLimitsServedBody(t *testing.T) {
+	files := map[string]string{"1_seed.up.sql.gz": gzipped(t, "TRUNCATE users;")}
+	if _, err := WithInstance(newTestBox(files), WithLimits(Limits{Forbidden: []string{"TRUNCATE"}})); err == nil {
+		t.Error("expected the decompressed body to be checked")
+	}
+}
+
+func TestLimitsQuery(t *testing.T) {
+	opts, err := queryOptions(map[string][]string{"maxbytes": {"100"}, "maxstmts": {"3"}, "forbid": {"drop database, TRUNCATE"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Driver{}
+	d.apply(opts)
+	if d.limits.MaxBytes != 100 || d.limits.MaxStatements != 3 || strings.Join(d.limits.Forbidden, ",") != "DROP DATABASE,TRUNCATE" {
+		t.Errorf("unexpected limits %+v", d.limits)
+	}
+	for _, param := range []string{"maxbytes", "maxstmts"} {
+		if _, err := queryOptions(map[string][]string{param: {"many"}}); err == nil {
+			t.Errorf("expected error for invalid %s", param)
+		}
+	}
+}
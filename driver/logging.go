@@ -0,0 +1,62 @@
+package driver
+
+import "github.com/golang-migrate/migrate/v4/source"
+
+// StructuredLogger receives the key events of the driver as a message
+// followed by alternating keys and values. A *slog.Logger satisfies it.
+type StructuredLogger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithStructuredLogger makes the driver report to l:
+//
+//	migration skipped       (debug) a file wasn't indexed: file, reason
+//	migration index built   (info)  the index was built: count, min, max, skipped
+//	migration read          (debug) a body was served: version, direction, identifier, file
+//	migration open failed   (error) a file couldn't be opened: file, error
+//
+// The messages of WithLogger are still sent to its logger.
+func WithStructuredLogger(l StructuredLogger) Option {
+	return func(d *Driver) {
+		d.slogger = l
+	}
+}
+
+// skipped reports a file that isn't indexed.
+func (d *Driver) skipped(file string, reason interface{}) {
+	d.logf("skipping %s: %v", file, reason)
+	if d.slogger != nil {
+		d.slogger.Debug("migration skipped", "file", file, "reason", reason)
+	}
+	d.skipEvent(file, reason)
+}
+
+// indexed reports a newly built index.
+func (d *Driver) indexed(idx *index) {
+	if d.slogger == nil {
+		return
+	}
+	r := idx.report
+	args := []interface{}{"count", r.Migrations}
+	if r.Versions > 0 {
+		args = append(args, "min", r.First, "max", r.Last)
+	}
+	args = append(args, "skipped", len(r.Skipped))
+	d.slogger.Info("migration index built", args...)
+}
+
+// served reports a body returned by a read.
+func (d *Driver) served(m *source.Migration) {
+	if d.slogger != nil {
+		d.slogger.Debug("migration read", "version", m.Version, "direction", string(m.Direction), "identifier", m.Identifier, "file", m.Raw)
+	}
+}
+
+// openFailed reports a file that couldn't be opened.
+func (d *Driver) openFailed(file string, err error) {
+	if d.slogger != nil {
+		d.slogger.Error("migration open failed", "file", file, "error", err)
+	}
+}
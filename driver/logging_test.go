@@ -0,0 +1,81 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// recordingStructuredLogger records events as "level msg key=value ...".
+type recordingStructuredLogger []string
+
+func (l *recordingStructuredLogger) record(level, msg string, args []interface{}) {
+	event := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		event += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	*l = append(*l, event)
+}
+
+func (l *recordingStructuredLogger) Debug(msg string, args ...interface{}) {
+	l.record("DEBUG", msg, args)
+}
+
+func (l *recordingStructuredLogger) Info(msg string, args ...interface{}) {
+	l.record("INFO", msg, args)
+}
+
+func (l *recordingStructuredLogger) Error(msg string, args ...interface{}) {
+	l.record("ERROR", msg, args)
+}
+
+func TestWithStructuredLogger(t *testing.T) {
+	var logger recordingStructuredLogger
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":    "1 up",
+		"3_users.up.sql":   "3 up",
+		"notes.sql":        "",
+		"README.md":        "",
+		"3_users.down.sql": "3 down",
+	})
+	d, err := WithInstance(box, WithStructuredLogger(&logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readDown(d)(3); err != nil {
+		t.Fatal(err)
+	}
+
+	// files are skipped in the order the box lists them
+	sort.Strings(logger[:2])
+	want := []string{
+		"DEBUG migration skipped file=README.md reason=extension not allowed",
+		"DEBUG migration skipped file=notes.sql reason=" + source.ErrParse.Error(),
+		"INFO migration index built count=3 min=1 max=3 skipped=2",
+		"DEBUG migration read version=3 direction=down identifier=users file=3_users.down.sql",
+	}
+	if strings.Join(logger, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected events\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(logger, "\n"))
+	}
+}
+
+func TestStructuredLoggerOpenFailed(t *testing.T) {
+	var logger recordingStructuredLogger
+	migs := []source.Migration{{Version: 1, Identifier: "a", Direction: source.Up, Raw: "1_a.up.sql"}}
+	opener := func(raw string) (io.ReadCloser, error) { return nil, errors.New("gone") }
+	d, err := WithMigrations(migs, opener, WithStructuredLogger(&logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.ReadUp(1); err == nil {
+		t.Fatal("expected open error")
+	}
+	if last := logger[len(logger)-1]; last != "ERROR migration open failed file=1_a.up.sql error=gone" {
+		t.Errorf("unexpected event %q", last)
+	}
+}
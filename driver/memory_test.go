@@ -0,0 +1,92 @@
+package driver
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestReadAllSharesMemoryFiles(t *testing.T) {
+	d := &Driver{}
+	data := []byte("CREATE TABLE a();")
+	f := newMemoryFile(data)
+	f.Read(make([]byte, 7))
+	rest, err := d.readAll(readCloser{Reader: f, Closer: f})
+	if err != nil || string(rest) != "TABLE a();" {
+		t.Fatalf("expected the unread part, got %q, %v", rest, err)
+	}
+	if &rest[0] != &data[7] {
+		t.Error("expected the data to be shared, not copied")
+	}
+	if n, _ := f.Read(make([]byte, 1)); n != 0 {
+		t.Error("expected the file to be read to the end")
+	}
+
+	copied, err := d.readAll(strings.NewReader("SELECT 1;"))
+	if err != nil || string(copied) != "SELECT 1;" {
+		t.Fatalf("expected the body, got %q, %v", copied, err)
+	}
+	if got := d.stats.BufferedBytes; got != 9 {
+		t.Errorf("expected 9 buffered bytes, got %d", got)
+	}
+}
+
+func TestMemoryStats(t *testing.T) {
+	body := strings.Repeat("INSERT INTO t VALUES (1);\n", 1000)
+	files := map[string][]byte{
+		"1_seed.up.sql":       []byte(body),
+		"2_grant.up.sql.tmpl": []byte("GRANT ALL TO {{.user}};"),
+		"3_users.up.sql":      []byte("CREATE TABLE users();"),
+	}
+	d, err := WithFiles(files, WithBodyCache(), WithTemplateData(map[string]interface{}{"user": "app"}), WithLimits(Limits{MaxStatements: 5000}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := len("GRANT ALL TO app;")
+	cached := int64(len(body) + rendered + len("CREATE TABLE users();"))
+	// the limits are checked before the index is published, past the cache
+	if s := d.Stats(); s.BufferedBytes != uint64(rendered) || s.CachedBytes != 0 {
+		t.Errorf("expected only the rendered template to be buffered, got %+v", s)
+	}
+
+	for _, v := range []uint{1, 1, 2, 3} {
+		if _, err := readUp(d)(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := d.Stats(); s.BufferedBytes != uint64(2*rendered) || s.CachedBytes != cached {
+		t.Errorf("expected reads to be served from the cache, got %+v", s)
+	}
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if cached := d.cache.bodies[bodyKey{1, "up"}]; &cached[0] != &files["1_seed.up.sql"][0] {
+		t.Error("expected the cache to share the stored body")
+	}
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Close()
+	if s := d.Stats(); s.CachedBytes != 0 {
+		t.Errorf("expected an empty cache after Close, got %d bytes", s.CachedBytes)
+	}
+}
+
+func TestMemoryStatsStreamedBox(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{"1_init.up.sql": "CREATE TABLE a();"}), WithLimits(Limits{MaxBytes: 100}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Stats().BufferedBytes; got != uint64(len("CREATE TABLE a();")) {
+		t.Errorf("expected the checked body to be buffered, got %d bytes", got)
+	}
+	if _, err := readUp(d)(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Stats().BufferedBytes; got != uint64(len("CREATE TABLE a();")) {
+		t.Errorf("expected the served body to be streamed, got %d bytes", got)
+	}
+}
@@ -0,0 +1,90 @@
+package driver
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// metadata returns the header metadata of the migration m, read from
+// its body like ReadUp and ReadDown would return it.
+func (d *Driver) metadata(m *source.Migration) (map[string]string, error) {
+	r, err := d.body(m)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return parseHeader(r)
+}
+
+// metadataIn is metadata reading the files of idx.
+func (d *Driver) metadataIn(idx *index, m *source.Migration) (map[string]string, error) {
+	r, err := d.loadFrom(idx, m)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return parseHeader(r)
+}
+
+// parseHeader returns the metadata in the comment lines at the top of
+// a migration body, such as
+//
+//	-- description: Add the users table
+//	-- ticket: OPS-1234
+//
+// Keys are lower-cased, and the values of repeated keys are joined with
+// ", ". The header ends at the first line that isn't a comment or blank,
+// and comment lines without a key are ignored. It returns nil for a body
+// without metadata.
+func parseHeader(r io.Reader) (map[string]string, error) {
+	var meta map[string]string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		key, value, ok := headerField(strings.TrimSpace(strings.TrimPrefix(line, "--")))
+		if !ok {
+			continue
+		}
+		if meta == nil {
+			meta = map[string]string{}
+		}
+		if prev, ok := meta[key]; ok {
+			value = prev + ", " + value
+		}
+		meta[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// headerField splits a header comment like "ticket: OPS-1234" into its
+// lower-cased key and value. Keys are single words of letters, digits,
+// dashes and underscores.
+func headerField(comment string) (string, string, bool) {
+	i := strings.IndexByte(comment, ':')
+	if i <= 0 {
+		return "", "", false
+	}
+	key := strings.ToLower(comment[:i])
+	for _, c := range key {
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_') {
+			return "", "", false
+		}
+	}
+	value := strings.TrimSpace(comment[i+1:])
+	if value == "" {
+		return "", "", false
+	}
+	return key, value, true
+}
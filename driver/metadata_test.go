@@ -0,0 +1,56 @@
+package driver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	for _, test := range []struct {
+		body string
+		want map[string]string
+	}{
+		{"CREATE TABLE a();", nil},
+		{"", nil},
+		{"-- just a comment\nCREATE TABLE a();", nil},
+		{
+			"-- Description: Add the users table\n-- author: jane\n\n-- ticket: OPS-1\n--ticket:OPS-2\nCREATE TABLE users();\n-- ticket: OPS-3\n",
+			map[string]string{"description": "Add the users table", "author": "jane", "ticket": "OPS-1, OPS-2"},
+		},
+		{"-- note: see https://example.com/x\n", map[string]string{"note": "see https://example.com/x"}},
+		{"-- not a key: value\n-- empty:\n", nil},
+	} {
+		got, err := parseHeader(strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: expected %v, got %v", test.body, test.want, got)
+		}
+	}
+}
+
+func TestListMetadata(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":   "-- ticket: OPS-1234\nCREATE TABLE a();",
+		"1_init.down.sql": "DROP TABLE a;",
+	})
+	d, err := WithInstance(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	if want := map[string]string{"ticket": "OPS-1234"}; !reflect.DeepEqual(files[0].Metadata, want) {
+		t.Errorf("expected %v, got %v", want, files[0].Metadata)
+	}
+	if files[1].Metadata != nil {
+		t.Errorf("expected no metadata for the down migration, got %v", files[1].Metadata)
+	}
+}
@@ -0,0 +1,139 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Stats are counters of the work done by a driver since it was created.
+type Stats struct {
+	// Migrations is the number of migrations currently indexed.
+	Migrations int
+	// UpReads and DownReads count the bodies served in each direction.
+	UpReads   uint64
+	DownReads uint64
+	// ReadBytes counts the bytes read from served bodies.
+	ReadBytes uint64
+	// ParseFailures counts the files skipped because they couldn't be parsed,
+	// every time the index was built.
+	ParseFailures uint64
+	// BufferedBytes counts the bytes the driver copied into memory to
+	// verify, decrypt, render or check bodies, or to read them from
+	// boxes that can only be read as a whole. Bodies served as stored
+	// by boxes holding them in memory aren't copied.
+	BufferedBytes uint64
+	// CachedBytes is the size of the bodies currently held by the body
+	// cache, see WithBodyCache. Bodies the box holds in memory are
+	// shared with it rather than copied.
+	CachedBytes int64
+}
+
+// stats guards the counters behind Stats.
+type stats struct {
+	mu sync.Mutex
+	Stats
+}
+
+// Stats returns the current counters of the driver.
+func (d *Driver) Stats() Stats {
+	d.mu.RLock()
+	indexed := d.migrations.count()
+	d.mu.RUnlock()
+
+	d.stats.mu.Lock()
+	s := d.stats.Stats
+	d.stats.mu.Unlock()
+	s.Migrations = indexed
+	s.CachedBytes = d.cache.size()
+	return s
+}
+
+// WriteTo writes s in the Prometheus text exposition format, so the
+// counters can be scraped without this package depending on a client
+// library. The packrprom module registers them with a Prometheus
+// registry instead.
+func (s Stats) WriteTo(w io.Writer) (int64, error) {
+	type sample struct {
+		labels string
+		value  interface{}
+	}
+	var written int64
+	for _, m := range []struct {
+		name, kind, help string
+		samples          []sample
+	}{
+		{"packr_migrations_indexed", "gauge", "Number of indexed migrations.",
+			[]sample{{"", s.Migrations}}},
+		{"packr_migration_reads_total", "counter", "Migration bodies served by direction.",
+			[]sample{{`{direction="up"}`, s.UpReads}, {`{direction="down"}`, s.DownReads}}},
+		{"packr_migration_read_bytes_total", "counter", "Bytes read from served migration bodies.",
+			[]sample{{"", s.ReadBytes}}},
+		{"packr_migration_parse_failures_total", "counter", "Files skipped because they couldn't be parsed.",
+			[]sample{{"", s.ParseFailures}}},
+		{"packr_migration_buffered_bytes_total", "counter", "Bytes of migration bodies copied into memory.",
+			[]sample{{"", s.BufferedBytes}}},
+		{"packr_migration_cached_bytes", "gauge", "Bytes of migration bodies held by the body cache.",
+			[]sample{{"", s.CachedBytes}}},
+	} {
+		n, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		for _, sample := range m.samples {
+			n, err := fmt.Fprintf(w, "%s%s %v\n", m.name, sample.labels, sample.value)
+			written += int64(n)
+			if err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// countRead records a body served for m.
+func (d *Driver) countRead(m *source.Migration) {
+	d.stats.mu.Lock()
+	if m.Direction == source.Down {
+		d.stats.DownReads++
+	} else {
+		d.stats.UpReads++
+	}
+	d.stats.mu.Unlock()
+}
+
+// countParseFailure records a file skipped because it couldn't be parsed.
+func (d *Driver) countParseFailure() {
+	d.stats.mu.Lock()
+	d.stats.ParseFailures++
+	d.stats.mu.Unlock()
+}
+
+// countBuffered records n bytes copied into memory.
+func (d *Driver) countBuffered(n int) {
+	if n == 0 {
+		return
+	}
+	d.stats.mu.Lock()
+	d.stats.BufferedBytes += uint64(n)
+	d.stats.mu.Unlock()
+}
+
+// countingReader adds the bytes read to the driver's counters.
+type countingReader struct {
+	io.ReadCloser
+	d *Driver
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.d.stats.mu.Lock()
+		r.d.stats.ReadBytes += uint64(n)
+		r.d.stats.mu.Unlock()
+	}
+	return n, err
+}
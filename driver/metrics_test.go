@@ -0,0 +1,68 @@
+package driver
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":   "1 up",
+		"1_init.down.sql": "1 down",
+		"2_users.up.sql":  "2 up",
+		"notes.sql":       "",
+		"README.md":       "",
+	})
+	d, err := WithInstance(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, read := range []func(uint) (string, error){readUp(d), readUp(d), readDown(d)} {
+		if _, err := read(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r, _, err := d.ReadUp(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Read(make([]byte, 2))
+	r.Close()
+
+	want := Stats{Migrations: 3, UpReads: 3, DownReads: 1, ReadBytes: 4 + 4 + 6 + 2, ParseFailures: 1}
+	if got := d.Stats(); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestStatsWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	s := Stats{Migrations: 3, UpReads: 2, DownReads: 1, ReadBytes: 12, ParseFailures: 4, BufferedBytes: 100, CachedBytes: 50}
+	n, err := s.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("expected %d bytes, got %d, %v", buf.Len(), n, err)
+	}
+	want := `# HELP packr_migrations_indexed Number of indexed migrations.
+# TYPE packr_migrations_indexed gauge
+packr_migrations_indexed 3
+# HELP packr_migration_reads_total Migration bodies served by direction.
+# TYPE packr_migration_reads_total counter
+packr_migration_reads_total{direction="up"} 2
+packr_migration_reads_total{direction="down"} 1
+# HELP packr_migration_read_bytes_total Bytes read from served migration bodies.
+# TYPE packr_migration_read_bytes_total counter
+packr_migration_read_bytes_total 12
+# HELP packr_migration_parse_failures_total Files skipped because they couldn't be parsed.
+# TYPE packr_migration_parse_failures_total counter
+packr_migration_parse_failures_total 4
+# HELP packr_migration_buffered_bytes_total Bytes of migration bodies copied into memory.
+# TYPE packr_migration_buffered_bytes_total counter
+packr_migration_buffered_bytes_total 100
+# HELP packr_migration_cached_bytes Bytes of migration bodies held by the body cache.
+# TYPE packr_migration_cached_bytes gauge
+packr_migration_cached_bytes 50
+`
+	if buf.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, buf.String())
+	}
+}
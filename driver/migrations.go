@@ -0,0 +1,94 @@
+package driver
+
+import (
+	"sort"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// migrations is an index of migrations by version with the methods of
+// source.Migrations. Unlike source.Migrations, which sorts all versions
+// again on every Append, it inserts each version at its place, so
+// indexing boxes with thousands of migrations doesn't take quadratic time.
+type migrations struct {
+	migrations map[uint]map[source.Direction]*source.Migration
+	// versions is sorted in ascending order.
+	versions []uint
+}
+
+func newMigrations() *migrations {
+	return &migrations{migrations: map[uint]map[source.Direction]*source.Migration{}}
+}
+
+// Append adds m to the index. It returns false if there already is
+// a migration with the same version and direction.
+func (i *migrations) Append(m *source.Migration) bool {
+	if m == nil {
+		return false
+	}
+	byDirection, ok := i.migrations[m.Version]
+	if !ok {
+		byDirection = map[source.Direction]*source.Migration{}
+		i.migrations[m.Version] = byDirection
+		at := sort.Search(len(i.versions), func(j int) bool { return i.versions[j] >= m.Version })
+		i.versions = append(i.versions, 0)
+		copy(i.versions[at+1:], i.versions[at:])
+		i.versions[at] = m.Version
+	}
+	if _, dup := byDirection[m.Direction]; dup {
+		return false
+	}
+	byDirection[m.Direction] = m
+	return true
+}
+
+func (i *migrations) First() (uint, bool) {
+	if len(i.versions) == 0 {
+		return 0, false
+	}
+	return i.versions[0], true
+}
+
+func (i *migrations) Prev(version uint) (uint, bool) {
+	at := i.find(version)
+	if at < 1 {
+		return 0, false
+	}
+	return i.versions[at-1], true
+}
+
+func (i *migrations) Next(version uint) (uint, bool) {
+	at := i.find(version)
+	if at < 0 || at+1 >= len(i.versions) {
+		return 0, false
+	}
+	return i.versions[at+1], true
+}
+
+func (i *migrations) Up(version uint) (*source.Migration, bool) {
+	m, ok := i.migrations[version][source.Up]
+	return m, ok
+}
+
+func (i *migrations) Down(version uint) (*source.Migration, bool) {
+	m, ok := i.migrations[version][source.Down]
+	return m, ok
+}
+
+// count returns the number of migrations in the index.
+func (i *migrations) count() int {
+	n := 0
+	for _, byDirection := range i.migrations {
+		n += len(byDirection)
+	}
+	return n
+}
+
+// find returns the position of version, or -1 if it isn't indexed.
+func (i *migrations) find(version uint) int {
+	at := sort.Search(len(i.versions), func(j int) bool { return i.versions[j] >= version })
+	if at < len(i.versions) && i.versions[at] == version {
+		return at
+	}
+	return -1
+}
@@ -0,0 +1,58 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// TestMigrations checks that migrations behaves like source.Migrations.
+func TestMigrations(t *testing.T) {
+	want := source.NewMigrations()
+	got := newMigrations()
+	for _, m := range []*source.Migration{
+		{Version: 7, Direction: source.Up},
+		{Version: 1, Direction: source.Up},
+		{Version: 1, Direction: source.Down},
+		{Version: 4, Direction: source.Down},
+		{Version: 7, Direction: source.Up},
+		{Version: 3, Direction: source.Direction("sideways")},
+		nil,
+	} {
+		if w, g := want.Append(m), got.Append(m); w != g {
+			t.Errorf("Append(%v): expected %v, got %v", m, w, g)
+		}
+	}
+
+	if w, g := first(want.First()), first(got.First()); w != g {
+		t.Errorf("First: expected %q, got %q", w, g)
+	}
+	for v := uint(0); v <= 8; v++ {
+		if w, g := first(want.Prev(v)), first(got.Prev(v)); w != g {
+			t.Errorf("Prev(%d): expected %q, got %q", v, w, g)
+		}
+		if w, g := first(want.Next(v)), first(got.Next(v)); w != g {
+			t.Errorf("Next(%d): expected %q, got %q", v, w, g)
+		}
+		if w, g := found(want.Up(v)), found(got.Up(v)); w != g {
+			t.Errorf("Up(%d): expected %v, got %v", v, w, g)
+		}
+		if w, g := found(want.Down(v)), found(got.Down(v)); w != g {
+			t.Errorf("Down(%d): expected %v, got %v", v, w, g)
+		}
+	}
+	if _, ok := newMigrations().First(); ok {
+		t.Error("expected no first version of an empty index")
+	}
+}
+
+func first(v uint, ok bool) string {
+	if !ok {
+		return "none"
+	}
+	return string(rune('0' + v))
+}
+
+func found(m *source.Migration, ok bool) bool {
+	return ok
+}
@@ -0,0 +1,138 @@
+package driver
+
+import (
+	"io"
+	"path"
+	"strings"
+	"sync"
+)
+
+// WithCaseInsensitiveNames makes the driver accept file names whose
+// extensions and direction are not lower case, like 0001_Init.UP.SQL
+// from boxes built on case-insensitive filesystems. The trailing
+// extensions of every name, those of up to four letters or digits like
+// .UP, .SQL and .GZ, are lower-cased before it is parsed, so the file
+// above is the up migration of version 1 with the identifier Init, and
+// the driver reports it as 0001_Init.up.sql. If two files only differ
+// in the case of their extensions, the one named in lower case wins and
+// the other keeps its name.
+func WithCaseInsensitiveNames() Option {
+	return func(d *Driver) {
+		d.foldCase = true
+	}
+}
+
+// WithNameNormalizer makes the driver pass every file name through
+// normalize before it is parsed, for example to turn the decomposed
+// unicode of names written on macOS into the composed form, so that
+// identifiers and dependencies match however the box was built:
+//
+//	driver.WithNameNormalizer(norm.NFC.String)
+//
+// using golang.org/x/text/unicode/norm. The driver reports the
+// normalized names and opens the files by their original names.
+func WithNameNormalizer(normalize func(name string) string) Option {
+	return func(d *Driver) {
+		d.normalizeName = normalize
+	}
+}
+
+// canonical returns the name file is parsed and reported as.
+func (d *Driver) canonical(file string) string {
+	if d.normalizeName != nil {
+		file = d.normalizeName(file)
+	}
+	if !d.foldCase {
+		return file
+	}
+	dir, base := path.Split(file)
+	end := len(base)
+	for {
+		i := strings.LastIndexByte(base[:end], '.')
+		if i <= 0 || end-i-1 > 4 || !isExtension(base[i+1:end]) {
+			break
+		}
+		end = i
+	}
+	return dir + base[:end] + strings.ToLower(base[end:])
+}
+
+func isExtension(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// folded wraps box so its files are listed under their canonical names,
+// if names are folded or normalized.
+func (d *Driver) folded(box Box) Box {
+	if !d.foldCase && d.normalizeName == nil {
+		return box
+	}
+	return &canonicalBox{base: box, canonical: d.canonical}
+}
+
+// canonicalBox lists the files of base under canonical names and opens
+// them by their original names.
+type canonicalBox struct {
+	base      Box
+	canonical func(name string) string
+
+	// mu guards originals, which maps the canonical names of the last
+	// listing to the original names.
+	mu        sync.RWMutex
+	originals map[string]string
+}
+
+func (b *canonicalBox) List() []string {
+	listed := b.base.List()
+	originals := make(map[string]string, len(listed))
+	names := make([]string, len(listed))
+	// Files already named canonically keep their names, the others get
+	// the canonical names not taken.
+	for pass := 0; pass < 2; pass++ {
+		for i, name := range listed {
+			c := b.canonical(name)
+			if (c == name) != (pass == 0) {
+				continue
+			}
+			if _, taken := originals[cleanPath(c)]; taken {
+				c = name
+			}
+			originals[cleanPath(c)] = name
+			names[i] = c
+		}
+	}
+	b.mu.Lock()
+	b.originals = originals
+	b.mu.Unlock()
+	return names
+}
+
+// original returns the original name of the file listed as name.
+func (b *canonicalBox) original(name string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if o, ok := b.originals[cleanPath(name)]; ok {
+		return o
+	}
+	return name
+}
+
+func (b *canonicalBox) Find(name string) ([]byte, error) {
+	return b.base.Find(b.original(name))
+}
+
+func (b *canonicalBox) open(name string) (io.ReadCloser, error) {
+	return openFile(b.base, b.original(name))
+}
+
+func (b *canonicalBox) size(name string) (int64, error) {
+	return fileSize(b.base, b.original(name))
+}
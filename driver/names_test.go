@@ -0,0 +1,83 @@
+package driver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestWithCaseInsensitiveNames(t *testing.T) {
+	files := map[string]string{
+		"0001_Init.UP.SQL":     "CREATE TABLE a();",
+		"0001_Init.DOWN.SQL":   "DROP TABLE a;",
+		"0002_Users.Up.Sql.GZ": gzipped(t, "CREATE TABLE users();"),
+		"3_dup.up.sql":         "lower",
+		"3_dup.UP.SQL":         "upper",
+		"R__Views.SQL":         "CREATE VIEW v;",
+	}
+	d, err := WithInstance(newTestBox(files), WithCaseInsensitiveNames())
+	if err != nil {
+		t.Fatal(err)
+	}
+	migs, err := d.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raws []string
+	for _, m := range migs {
+		raws = append(raws, m.Raw)
+	}
+	if want := []string{"0001_Init.up.sql", "0001_Init.down.sql", "0002_Users.up.sql.gz", "3_dup.up.sql"}; !reflect.DeepEqual(raws, want) {
+		t.Errorf("expected %v, got %v", want, raws)
+	}
+	if migs[0].Identifier != "Init" || migs[1].Direction != source.Down {
+		t.Errorf("unexpected migrations %+v", migs[:2])
+	}
+
+	read := readUp(d)
+	for version, want := range map[uint]string{1: "CREATE TABLE a();", 2: "CREATE TABLE users();", 3: "lower"} {
+		if body, err := read(version); err != nil || body != want {
+			t.Errorf("expected %q for version %d, got %q, %v", want, version, body, err)
+		}
+	}
+	if r, err := d.ReadRepeatable("Views"); err != nil {
+		t.Errorf("expected the repeatable migration, got %v", err)
+	} else {
+		r.Close()
+	}
+
+	plain, err := WithInstance(newTestBox(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := plain.First(); err != nil || v != 3 {
+		t.Errorf("expected only the lower-case file without the option, got %d, %v", v, err)
+	}
+}
+
+func TestWithNameNormalizer(t *testing.T) {
+	// composes the decomposed accents macOS writes
+	nfc := strings.NewReplacer("e\u0301", "é", "e\u0300", "è").Replace
+	files := map[string]string{
+		"1_cafe\u0301.up.sql":  "CREATE TABLE cafés();",
+		"2_menu.up.sql":        "CREATE TABLE menu();",
+		"3_cre\u0300me.UP.sql": "CREATE TABLE crèmes();",
+	}
+	d, err := WithInstance(newTestBox(files), WithNameNormalizer(nfc), WithCaseInsensitiveNames())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, identifier, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if identifier != "café" {
+		t.Errorf("expected the composed identifier, got %q", identifier)
+	}
+	if _, identifier, err := d.ReadUp(3); err != nil || identifier != "crème" {
+		t.Errorf("expected the composed identifier of version 3, got %q, %v", identifier, err)
+	}
+}
@@ -0,0 +1,97 @@
+package driver
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// utf8BOM is the byte order mark some Windows editors put in front
+// of UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// WithNormalization normalizes the bodies returned by ReadUp and
+// ReadDown: a leading UTF-8 byte order mark is removed, CRLF and CR line
+// endings become LF, and a non-empty body always ends with a newline.
+// This keeps migrations authored on Windows from upsetting database
+// drivers. Bodies are normalized after they are decoded to UTF-8 and
+// before templates are rendered. Checksums and signatures are still
+// those of the stored files.
+func WithNormalization() Option {
+	return func(d *Driver) {
+		d.normalized = true
+	}
+}
+
+// normalize is the body step applying WithNormalization.
+func (d *Driver) normalize(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error) {
+	if !d.normalized {
+		return r, nil
+	}
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(utf8BOM))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if bytes.Equal(head, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	return readCloser{Reader: &normalizer{r: br}, Closer: r}, nil
+}
+
+// normalizer converts line endings to LF and adds a final newline.
+type normalizer struct {
+	r io.Reader
+	// buf holds normalized bytes not returned yet, read into chunk.
+	buf, chunk []byte
+	// cr is set if the last byte read was a CR, which was already
+	// replaced by LF.
+	cr bool
+	// last is the last byte returned, zero before the first.
+	last byte
+	eof  bool
+}
+
+func (n *normalizer) Read(p []byte) (int, error) {
+	for len(n.buf) == 0 {
+		if n.eof {
+			return 0, io.EOF
+		}
+		if len(n.chunk) < len(p) {
+			n.chunk = make([]byte, len(p))
+		}
+		k, err := n.r.Read(n.chunk[:len(p)])
+		n.buf = n.buf[:0]
+		for _, c := range n.chunk[:k] {
+			switch {
+			case c == '\r':
+				n.buf = append(n.buf, '\n')
+			case c == '\n' && n.cr:
+			default:
+				n.buf = append(n.buf, c)
+			}
+			n.cr = c == '\r'
+		}
+		if err == io.EOF {
+			n.eof = true
+			last := n.last
+			if len(n.buf) > 0 {
+				last = n.buf[len(n.buf)-1]
+			}
+			if last != 0 && last != '\n' {
+				n.buf = append(n.buf, '\n')
+			}
+		} else if err != nil {
+			return 0, err
+		}
+		if len(p) == 0 {
+			return 0, nil
+		}
+	}
+	k := copy(p, n.buf)
+	n.buf = n.buf[k:]
+	n.last = p[k-1]
+	return k, nil
+}
@@ -0,0 +1,49 @@
+package driver
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWithNormalization(t *testing.T) {
+	for _, test := range []struct {
+		body, want string
+	}{
+		{"", ""},
+		{"CREATE TABLE a();", "CREATE TABLE a();\n"},
+		{"\xEF\xBB\xBFCREATE TABLE a();\r\nCREATE TABLE b();\r\n", "CREATE TABLE a();\nCREATE TABLE b();\n"},
+		{"a\rb\r\n\r\nc", "a\nb\n\nc\n"},
+		{"a\r", "a\n"},
+		{"\xEF\xBB\xBF", ""},
+		{"\xEF\xBB", "\xEF\xBB\n"},
+	} {
+		d, err := WithInstance(newTestBox(map[string]string{"1_a.up.sql": test.body}), WithNormalization())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := readUp(d)(1); err != nil || got != test.want {
+			t.Errorf("%q: expected %q, got %q, %v", test.body, test.want, got, err)
+		}
+	}
+}
+
+func TestNormalizerSmallReads(t *testing.T) {
+	want := "a\nb\n\nc\n"
+	got, err := ioutil.ReadAll(iotest.OneByteReader(&normalizer{r: iotest.OneByteReader(strings.NewReader("a\r\nb\r\n\rc"))}))
+	if err != nil || string(got) != want {
+		t.Errorf("expected %q, got %q, %v", want, got, err)
+	}
+}
+
+func TestNormalizationOff(t *testing.T) {
+	body := "\xEF\xBB\xBFa\r\n"
+	d, err := WithInstance(newTestBox(map[string]string{"1_a.up.sql": body}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readUp(d)(1); err != nil || got != body {
+		t.Errorf("expected the body unchanged by default, got %q, %v", got, err)
+	}
+}
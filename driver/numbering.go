@@ -0,0 +1,99 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NumberingPolicy is a convention for migration versions.
+type NumberingPolicy int
+
+const (
+	// AnyNumbering accepts every version, which is the default.
+	AnyNumbering NumberingPolicy = iota
+	// SequenceNumbering requires sequence numbers like 1, 2 and 3 with
+	// fewer than ten digits, so they can't be mistaken for timestamps.
+	SequenceNumbering
+	// UnixNumbering requires Unix timestamps in seconds with ten digits,
+	// like 1704888000.
+	UnixNumbering
+	// DateTimeNumbering requires valid timestamps in the form
+	// yyyymmddhhmmss, like 20240110120000.
+	DateTimeNumbering
+)
+
+// numberingPolicies are the names of the policies in URLs.
+var numberingPolicies = map[string]NumberingPolicy{
+	"any":      AnyNumbering,
+	"sequence": SequenceNumbering,
+	"unix":     UnixNumbering,
+	"datetime": DateTimeNumbering,
+}
+
+// ParseNumberingPolicy returns the policy named s: any, sequence, unix
+// or datetime, as in the numbering URL parameter.
+func ParseNumberingPolicy(s string) (NumberingPolicy, error) {
+	p, ok := numberingPolicies[s]
+	if !ok {
+		return AnyNumbering, fmt.Errorf("unknown numbering policy '%s'", s)
+	}
+	return p, nil
+}
+
+func (p NumberingPolicy) String() string {
+	switch p {
+	case SequenceNumbering:
+		return "sequence"
+	case UnixNumbering:
+		return "unix timestamp"
+	case DateTimeNumbering:
+		return "yyyymmddhhmmss timestamp"
+	}
+	return "any"
+}
+
+// allows reports whether version follows the policy.
+func (p NumberingPolicy) allows(version uint) bool {
+	switch p {
+	case SequenceNumbering:
+		return version < 1e9
+	case UnixNumbering:
+		return version >= 1e9 && uint64(version) < 1e10
+	case DateTimeNumbering:
+		s := strconv.FormatUint(uint64(version), 10)
+		_, err := time.Parse("20060102150405", s)
+		return len(s) == 14 && err == nil
+	}
+	return true
+}
+
+// WithNumberingPolicy makes the driver check that every migration
+// version follows p when it is created, failing with an error naming
+// the offending files. This catches a sequence number mixed in with
+// timestamps, which would silently sort before or after the others.
+func WithNumberingPolicy(p NumberingPolicy) Option {
+	return func(d *Driver) {
+		d.numbering = p
+	}
+}
+
+// checkNumbering checks the versions against the numbering policy.
+func (d *Driver) checkNumbering(idx *index) error {
+	if d.numbering == AnyNumbering {
+		return nil
+	}
+	var offending []string
+	for _, m := range idx.list() {
+		if !d.numbering.allows(m.Version) {
+			offending = append(offending, m.Raw)
+		}
+	}
+	if len(offending) == 0 {
+		return nil
+	}
+	sort.Strings(offending)
+	return fmt.Errorf("migrations not following %s numbering: %s", d.numbering, strings.Join(offending, ", "))
+}
@@ -0,0 +1,61 @@
+package driver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNumberingPolicy(t *testing.T) {
+	for _, c := range []struct {
+		policy  NumberingPolicy
+		version uint
+		allowed bool
+	}{
+		{AnyNumbering, 20240110120000, true},
+		{SequenceNumbering, 42, true},
+		{SequenceNumbering, 1704888000, false},
+		{UnixNumbering, 1704888000, true},
+		{UnixNumbering, 42, false},
+		{UnixNumbering, 20240110120000, false},
+		{DateTimeNumbering, 20240110120000, true},
+		{DateTimeNumbering, 20241310120000, false},
+		{DateTimeNumbering, 1704888000, false},
+	} {
+		if got := c.policy.allows(c.version); got != c.allowed {
+			t.Errorf("expected %s numbering to allow %d: %v, got %v", c.policy, c.version, c.allowed, got)
+		}
+	}
+}
+
+func TestWithNumberingPolicy(t *testing.T) {
+	files := map[string]string{
+		"20240110120000_init.up.sql":  "",
+		"20240111090000_users.up.sql": "",
+		"3_orders.up.sql":             "",
+	}
+	_, err := WithInstance(newTestBox(files), WithNumberingPolicy(DateTimeNumbering))
+	if err == nil {
+		t.Fatal("expected error for sequence number among timestamps")
+	}
+	if !strings.Contains(err.Error(), "3_orders.up.sql") || strings.Contains(err.Error(), "users") {
+		t.Errorf("expected only the offending file in %q", err)
+	}
+
+	delete(files, "3_orders.up.sql")
+	if _, err := WithInstance(newTestBox(files), WithNumberingPolicy(DateTimeNumbering)); err != nil {
+		t.Error(err)
+	}
+	if _, err := WithInstance(newTestBox(files), WithNumberingPolicy(SequenceNumbering)); err == nil {
+		t.Error("expected error for timestamps with sequence numbering")
+	}
+}
+
+func TestParseNumberingPolicy(t *testing.T) {
+	p, err := ParseNumberingPolicy("unix")
+	if err != nil || p != UnixNumbering {
+		t.Errorf("expected unix numbering, got %v, %v", p, err)
+	}
+	if _, err := ParseNumberingPolicy("roman"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
@@ -0,0 +1,346 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	nurl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Option configures a driver created by WithInstance or WithMigrations.
+type Option func(d *Driver)
+
+// WithReadConcurrency limits the number of migration bodies returned
+// by ReadUp and ReadDown that may be open at the same time.
+// Further reads block until a previously returned body is closed.
+// A value of zero or less means no limit, which is the default.
+func WithReadConcurrency(n int) Option {
+	return func(d *Driver) {
+		if n <= 0 {
+			d.readers = nil
+			return
+		}
+		d.readers = make(chan struct{}, n)
+	}
+}
+
+// WithRoot makes the driver serve only the files in the directory root
+// inside the box. Migration file names are resolved relative to root,
+// so packr://migrations?root=db/schema reads db/schema/1_init.up.sql
+// from the migrations box as version 1. Files in subdirectories of
+// root are not considered.
+func WithRoot(root string) Option {
+	return func(d *Driver) {
+		d.root = root
+	}
+}
+
+// WithSubdir scopes the driver to the directory dir inside the box,
+// for example assets/db/postgres in a box holding other assets too.
+// If a root or group is set as well, dir is relative to them: root db
+// and dir postgres read migrations from db/postgres.
+func WithSubdir(dir string) Option {
+	return func(d *Driver) {
+		d.subdir = dir
+	}
+}
+
+// WithStrictParsing makes the driver fail with an error listing every
+// file that isn't a valid migration file name, instead of silently
+// skipping such files. Files in subdirectories of the migration
+// directory, the manifest, seeds and the baseline file are still
+// skipped.
+func WithStrictParsing() Option {
+	return func(d *Driver) {
+		d.strict = true
+	}
+}
+
+// defaultExtensions are the extensions of the files considered
+// unless WithExtensions is used.
+var defaultExtensions = map[string]bool{".sql": true}
+
+// WithExtensions restricts the driver to files with one of the given
+// extensions, such as ".cql" or ".js", instead of the default ".sql".
+// Other files, like notes or editor swap files next to the migrations,
+// are ignored, even in strict mode. Without any extension, every file
+// is considered.
+// Compressed and encrypted files are matched by the extension below
+// their compression and encryption formats and below .tmpl for
+// templates, so 1_init.up.sql.gz and 1_init.up.sql.enc have the
+// extension ".sql".
+func WithExtensions(exts ...string) Option {
+	return func(d *Driver) {
+		d.extensions = make(map[string]bool, len(exts))
+		for _, ext := range exts {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			d.extensions[ext] = true
+		}
+	}
+}
+
+// allowed reports whether file has one of the extensions considered.
+func (d *Driver) allowed(file string) bool {
+	exts := d.extensions
+	if exts == nil {
+		exts = defaultExtensions
+	}
+	return len(exts) == 0 || exts[d.contentExt(file)]
+}
+
+// Logger receives diagnostic messages from the driver.
+// A *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithLogger makes the driver report what it does to l,
+// for example which files it skipped.
+func WithLogger(l Logger) Option {
+	return func(d *Driver) {
+		d.logger = l
+	}
+}
+
+func (d *Driver) logf(format string, v ...interface{}) {
+	if d.logger != nil {
+		d.logger.Printf(format, v...)
+	}
+}
+
+// queryOptions returns the options set by the query parameters
+// of a URL given to Open.
+func queryOptions(query nurl.Values) ([]Option, error) {
+	var opts []Option
+	if root := query.Get("root"); root != "" {
+		opts = append(opts, WithRoot(root))
+	}
+	if group := query.Get("group"); group != "" {
+		opts = append(opts, WithGroup(group))
+	}
+	if v := query.Get("groups"); v != "" {
+		var groups []string
+		for _, group := range strings.Split(v, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				groups = append(groups, group)
+			}
+		}
+		opts = append(opts, WithKnownGroups(groups...))
+	}
+	if dir := query.Get("dir"); dir != "" {
+		opts = append(opts, WithSubdir(dir))
+	}
+	if v := query.Get("strict"); v != "" {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for strict '%s': %v", v, err)
+		}
+		if strict {
+			opts = append(opts, WithStrictParsing())
+		}
+	}
+	if v := query.Get("seeds"); v != "" {
+		seeds, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for seeds '%s': %v", v, err)
+		}
+		if seeds {
+			opts = append(opts, WithSeeds())
+		}
+	}
+	if env := query.Get("env"); env != "" {
+		opts = append(opts, WithEnvironment(env))
+	}
+	if v := query.Get("numbering"); v != "" {
+		policy, ok := numberingPolicies[v]
+		if !ok {
+			return nil, fmt.Errorf("invalid value for numbering '%s'", v)
+		}
+		opts = append(opts, WithNumberingPolicy(policy))
+	}
+	if v := query.Get("downs"); v != "" {
+		policy, below := v, ""
+		if i := strings.Index(v, ":"); i >= 0 {
+			policy, below = v[:i], v[i+1:]
+		}
+		switch policy {
+		case "required":
+			var version uint64
+			if below != "" {
+				var err error
+				if version, err = strconv.ParseUint(below, 10, 0); err != nil {
+					return nil, fmt.Errorf("invalid value for downs '%s': %v", v, err)
+				}
+			}
+			opts = append(opts, WithRequiredDowns(uint(version)))
+		case "forbidden":
+			if below != "" {
+				return nil, fmt.Errorf("invalid value for downs '%s'", v)
+			}
+			opts = append(opts, WithoutDowns())
+		case "any":
+		default:
+			return nil, fmt.Errorf("invalid value for downs '%s'", v)
+		}
+	}
+	var limits Limits
+	limited := false
+	if v := query.Get("maxbytes"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for maxbytes '%s': %v", v, err)
+		}
+		limits.MaxBytes, limited = n, true
+	}
+	if v := query.Get("maxstmts"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for maxstmts '%s': %v", v, err)
+		}
+		limits.MaxStatements, limited = n, true
+	}
+	if v := query.Get("forbid"); v != "" {
+		for _, statement := range strings.Split(v, ",") {
+			if statement = strings.TrimSpace(statement); statement != "" {
+				limits.Forbidden = append(limits.Forbidden, statement)
+			}
+		}
+		limited = true
+	}
+	if limited {
+		opts = append(opts, WithLimits(limits))
+	}
+	if v := query.Get("deep"); v == "sql" {
+		opts = append(opts, WithDeepValidation(CheckSQL))
+	} else if v != "" {
+		deep, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for deep '%s': %v", v, err)
+		}
+		if deep {
+			opts = append(opts, WithDeepValidation(nil))
+		}
+	}
+	if v := query.Get("requires"); v != "" {
+		requires, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for requires '%s': %v", v, err)
+		}
+		if requires {
+			opts = append(opts, WithDependencies())
+		}
+	}
+	if v := query.Get("idcheck"); v != "" {
+		check, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for idcheck '%s': %v", v, err)
+		}
+		if check {
+			opts = append(opts, WithIdentifierCheck())
+		}
+	}
+	if manifest := query.Get("manifest"); manifest != "" {
+		opts = append(opts, WithManifestFile(manifest))
+	}
+	if name := query.Get("keyenv"); name != "" {
+		opts = append(opts, WithDecryptionKeyEnv(name))
+	}
+	if archive := query.Get("archive"); archive != "" {
+		opts = append(opts, WithArchive(archive))
+	}
+	if overlay := query.Get("overlay"); overlay != "" {
+		opts = append(opts, WithOverlay(overlay))
+	}
+	for _, param := range []struct {
+		name   string
+		option func(uint) Option
+	}{{"min", WithMinVersion}, {"max", WithMaxVersion}, {"baseline", WithBaseline}} {
+		v := query.Get(param.name)
+		if v == "" {
+			continue
+		}
+		version, err := strconv.ParseUint(v, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s '%s': %v", param.name, v, err)
+		}
+		opts = append(opts, param.option(uint(version)))
+	}
+	if v := query.Get("skip"); v != "" {
+		var versions []uint
+		for _, field := range strings.Split(v, ",") {
+			version, err := strconv.ParseUint(strings.TrimSpace(field), 10, 0)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for skip '%s': %v", v, err)
+			}
+			versions = append(versions, uint(version))
+		}
+		opts = append(opts, WithSkipVersions(versions...))
+	}
+	if v := query.Get("remap"); v != "" {
+		remap := map[uint]uint{}
+		for _, field := range strings.Split(v, ",") {
+			pair := strings.SplitN(strings.TrimSpace(field), ":", 2)
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("invalid value for remap '%s'", v)
+			}
+			old, err := strconv.ParseUint(pair[0], 10, 0)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for remap '%s': %v", v, err)
+			}
+			version, err := strconv.ParseUint(pair[1], 10, 0)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for remap '%s': %v", v, err)
+			}
+			remap[uint(old)] = uint(version)
+		}
+		opts = append(opts, WithVersionMap(remap))
+	}
+	if v, ok := query["ext"]; ok {
+		var exts []string
+		for _, ext := range strings.Split(strings.Join(v, ","), ",") {
+			if ext = strings.TrimSpace(ext); ext != "" {
+				exts = append(exts, ext)
+			}
+		}
+		opts = append(opts, WithExtensions(exts...))
+	}
+	if v := query.Get("parallel"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for parallel '%s': %v", v, err)
+		}
+		opts = append(opts, WithParallelism(n))
+	}
+	if v := query.Get("reload"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for reload '%s': %v", v, err)
+		}
+		opts = append(opts, WithHotReload(interval))
+	}
+	return opts, nil
+}
+
+func (d *Driver) apply(opts []Option) {
+	for _, opt := range opts {
+		opt(d)
+	}
+}
+
+// slotReader gives back its reader slot when it is closed.
+type slotReader struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *slotReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
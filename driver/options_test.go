@@ -0,0 +1,221 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestWithReadConcurrency(t *testing.T) {
+	d := newTestDriver(t, map[string]string{
+		"1_a.up.sql": "1 up",
+		"2_b.up.sql": "2 up",
+	}, WithReadConcurrency(1))
+
+	first, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened := make(chan io.ReadCloser)
+	go func() {
+		r, _, err := d.ReadUp(2)
+		if err != nil {
+			t.Error(err)
+		}
+		opened <- r
+	}()
+
+	select {
+	case <-opened:
+		t.Fatal("second read should block while the first is open")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// closing twice must not release a second slot
+	first.Close()
+
+	select {
+	case r := <-opened:
+		r.Close()
+	case <-time.After(time.Second):
+		t.Fatal("second read did not proceed after the first was closed")
+	}
+}
+
+func TestWithStrictParsing(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":     "",
+		"2-typo.up.sql":     "",
+		"3_missing_dir.sql": "",
+	})
+	if _, err := WithInstance(box); err != nil {
+		t.Fatalf("expected lenient parsing by default, got %v", err)
+	}
+
+	_, err := WithInstance(box, WithStrictParsing())
+	if err == nil {
+		t.Fatal("expected error in strict mode")
+	}
+	for _, file := range []string{"2-typo.up.sql", "3_missing_dir.sql"} {
+		if !strings.Contains(err.Error(), file) {
+			t.Errorf("expected %s in %q", file, err)
+		}
+	}
+	if strings.Contains(err.Error(), "1_init.up.sql") {
+		t.Errorf("did not expect valid file in %q", err)
+	}
+}
+
+func TestStrictParsingSkipsOtherFiles(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"db/1_init.up.sql":        "CREATE TABLE a();",
+		"db/2_users.up.sql":       "CREATE TABLE users();",
+		"db/sub/2_x.up.sql":       "",
+		"db/baseline/schema.sql":  "CREATE TABLE a(); CREATE TABLE users();",
+		"db/seeds/1_users.up.sql": "",
+		"db/checksums.sha256":     "",
+	})
+	if _, err := WithInstance(box, WithRoot("db"), WithStrictParsing()); err != nil {
+		t.Errorf("expected files in subdirectories to be skipped, got %v", err)
+	}
+	if _, err := WithInstance(box, WithRoot("db"), WithStrictParsing(), WithBaselineFile(2, "baseline/schema.sql")); err != nil {
+		t.Errorf("expected the baseline file to be skipped, got %v", err)
+	}
+}
+
+func TestNestedFilesAreSkipped(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":     "",
+		"1_x/2_y.up.sql":    "",
+		"old/3_init.up.sql": "",
+	})
+	for name, opts := range map[string][]Option{
+		"default": nil,
+		"regex":   {WithRegex(regexp.MustCompile(`(\d+)_(.*)\.(up|down)\.sql`))},
+		"parser": {WithParser(func(file string) (*source.Migration, error) {
+			return source.DefaultParse(path.Base(file))
+		})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d, err := WithInstance(box, append(opts, WithStrictParsing())...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := d.Next(1); err == nil {
+				t.Error("expected files in subdirectories to be skipped")
+			}
+			want := map[string]string{"1_x/2_y.up.sql": "in a subdirectory", "old/3_init.up.sql": "in a subdirectory"}
+			got := map[string]string{}
+			for _, f := range d.Report().Skipped {
+				got[f.File] = f.Reason
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected skipped files %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestWithExtensions(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":      "",
+		"2_seed.up.cql":      "",
+		"3_notes.up.md":      "",
+		"README.md":          "",
+		".1_init.up.sql.swp": "",
+	})
+	d, err := WithInstance(box, WithExtensions(".sql", "cql"), WithStrictParsing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.Next(1); err != nil || v != 2 {
+		t.Errorf("expected next version 2, got %d, %v", v, err)
+	}
+	if _, err := d.Next(2); err == nil {
+		t.Error("expected .md files to be ignored")
+	}
+}
+
+func TestDefaultExtensions(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":      "",
+		"2_seed.up.cql":      "",
+		"3_notes.up.md":      "",
+		"notes.md":           "",
+		".1_init.up.sql.swp": "",
+	})
+	d, err := WithInstance(box, WithStrictParsing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Next(1); err == nil {
+		t.Error("expected only .sql files to be considered")
+	}
+
+	d, err = WithInstance(box, WithExtensions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.Next(2); err != nil || v != 3 {
+		t.Errorf("expected all files to be considered, got %d, %v", v, err)
+	}
+}
+
+func TestOpenWithExtensions(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	mustWriteFile(t, tmpDir, "1_init.up.sql", "")
+	mustWriteFile(t, tmpDir, "2_seed.up.cql", "")
+	mustWriteFile(t, tmpDir, "3_seed.up.js", "")
+
+	p := &Driver{}
+	for query, want := range map[string]uint{"": 1, "?ext=cql,.js": 2, "?ext=": 3} {
+		d, err := p.Open("packr://" + tmpDir + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		infos, err := d.(*Driver).Describe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uint(len(infos)) != want {
+			t.Errorf("%q: expected %d migrations, got %d", query, want, len(infos))
+		}
+	}
+}
+
+type recordingLogger []string
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
+
+func TestWithLogger(t *testing.T) {
+	var logger recordingLogger
+	box := newTestBox(map[string]string{
+		"1_init.up.sql": "",
+		"README.md":     "",
+	})
+	if _, err := WithInstance(box, WithLogger(&logger)); err != nil {
+		t.Fatal(err)
+	}
+	if len(logger) != 1 || !strings.Contains(logger[0], "README.md") {
+		t.Errorf("expected skipped README.md to be logged, got %q", logger)
+	}
+}
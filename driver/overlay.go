@@ -0,0 +1,83 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+// WithOverlay makes the files in the local directory dir take precedence
+// over the files with the same name in the migration directory of the box,
+// and adds the files only present in dir. This lets migrations be edited
+// on disk during development without regenerating the box.
+func WithOverlay(dir string) Option {
+	return func(d *Driver) {
+		d.overlay = dir
+	}
+}
+
+// overlayBox serves the files of the directory dir inside base from
+// the top Box where it has them.
+type overlayBox struct {
+	base Box
+	top  Box
+	dir  string
+}
+
+func newOverlayBox(base Box, overlay, dir string) (Box, error) {
+	fi, err := os.Stat(overlay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid overlay: %v", err)
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("invalid overlay: %s is not a directory", overlay)
+	}
+	return overlayBox{base: base, top: fsBox{os.DirFS(overlay)}, dir: cleanPath(dir)}, nil
+}
+
+func (b overlayBox) List() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range b.top.List() {
+		name = path.Join(b.dir, name)
+		seen[name] = true
+		names = append(names, name)
+	}
+	for _, name := range b.base.List() {
+		if !seen[cleanPath(name)] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (b overlayBox) Find(name string) ([]byte, error) {
+	if rel, ok := inDir(name, b.dir); ok {
+		data, err := b.top.Find(rel)
+		if err == nil || !os.IsNotExist(err) {
+			return data, err
+		}
+	}
+	return b.base.Find(name)
+}
+
+func (b overlayBox) open(name string) (io.ReadCloser, error) {
+	if rel, ok := inDir(name, b.dir); ok {
+		r, err := openFile(b.top, rel)
+		if err == nil || !os.IsNotExist(err) {
+			return r, err
+		}
+	}
+	return openFile(b.base, name)
+}
+
+func (b overlayBox) size(name string) (int64, error) {
+	if rel, ok := inDir(name, b.dir); ok {
+		n, err := fileSize(b.top, rel)
+		if err == nil || !os.IsNotExist(err) {
+			return n, err
+		}
+	}
+	return fileSize(b.base, name)
+}
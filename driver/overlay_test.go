@@ -0,0 +1,66 @@
+package driver
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestWithOverlay(t *testing.T) {
+	overlay, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(overlay)
+	mustWriteFile(t, overlay, "2_users.up.sql", "edited on disk")
+	mustWriteFile(t, overlay, "3_new.up.sql", "new on disk")
+
+	box := newTestBox(map[string]string{
+		"db/1_init.up.sql":  "embedded init",
+		"db/2_users.up.sql": "embedded users",
+		"2_users.up.sql":    "outside the migration directory",
+	})
+	d, err := WithInstance(box, WithRoot("db"), WithOverlay(overlay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for version, want := range map[uint]string{1: "embedded init", 2: "edited on disk", 3: "new on disk"} {
+		if body, err := readUp(d)(version); err != nil || body != want {
+			t.Errorf("version %d: expected %q, got %q, %v", version, want, body, err)
+		}
+	}
+}
+
+func TestOpenWithOverlay(t *testing.T) {
+	boxDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(boxDir)
+	overlay, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(overlay)
+	mustWriteFile(t, boxDir, "1_init.up.sql", "box")
+	mustWriteFile(t, overlay, "1_init.up.sql", "overlay")
+
+	p := &Driver{}
+	d, err := p.Open("packr://" + boxDir + "?overlay=" + url.QueryEscape(overlay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if body, _ := ioutil.ReadAll(r); string(body) != "overlay" {
+		t.Errorf("expected overlay body, got %q", body)
+	}
+
+	if _, err := p.Open("packr://" + boxDir + "?overlay=" + url.QueryEscape(overlay+"/missing")); err == nil {
+		t.Error("expected error for missing overlay directory")
+	}
+}
@@ -1,82 +1,424 @@
 package driver
 
 import (
-	"bytes"
+	"context"
+	"crypto/ed25519"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"io/fs"
+	"net/http"
+	nurl "net/url"
 	"os"
+	"path"
+	"regexp"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/gobuffalo/packr"
 	"github.com/golang-migrate/migrate/v4/source"
+	"golang.org/x/text/encoding"
 )
 
-func init() {
-	source.Register("packr", &packrDriver{})
+// Driver is a source.Driver serving migrations from a packr box.
+//
+// It is safe for concurrent use: any number of goroutines may call its
+// methods and read bodies at the same time, also while boxes are added
+// or the index is reloaded. Each call sees either the index before or
+// after such a change, never a mix of both, and a body that was returned
+// keeps reading the file it was opened for. Options must not be applied
+// to a driver that is already in use.
+type Driver struct {
+	// build serializes changes to the index; origins are the sets
+	// of migrations it is built from, see merge.
+	build   sync.Mutex
+	origins []origin
+
+	// mu guards the index and the functions used to access
+	// the files it refers to, which change when boxes are added
+	// or the index is rebuilt. An index is never modified once
+	// built, so it can be used after mu is released.
+	mu          sync.RWMutex
+	migrations  *migrations
+	open        func(raw string) (io.ReadCloser, error)
+	size        func(raw string) (int64, error)
+	repeatables []*source.Migration
+	// baselineParts are the up migrations squashed into the baseline.
+	baselineParts []*source.Migration
+	report        Report
+	// fingerprint caches Fingerprint until the index changes.
+	fingerprint string
+	// sums are the checksums of the indexed files reported in events,
+	// nil without WithEvents.
+	sums   Manifest
+	closed bool
+
+	// readers bounds the number of open migration bodies.
+	// It is nil when reads are unlimited.
+	readers chan struct{}
+
+	// root and subdir locate the directory inside the box
+	// the migrations are read from, see dir.
+	root   string
+	subdir string
+
+	sniff  func(head []byte) (encoding.Encoding, error)
+	header *regexp.Regexp
+
+	encodeVersion func(raw string) (uint, error)
+	decodeVersion func(version uint) string
+	parser        func(raw string) (*source.Migration, error)
+	regex         *regexp.Regexp
+	goose         bool
+
+	// strict makes unparseable files an error instead of skipping them.
+	strict bool
+	// foldCase and normalizeName make the names of box files canonical,
+	// see canonical.
+	foldCase      bool
+	normalizeName func(name string) string
+	// sanitize and matchIdentifiers check the identifiers of migrations,
+	// see identifiers.go.
+	sanitize         Sanitizer
+	matchIdentifiers bool
+	// parallelism is the number of workers parsing a box, see WithParallelism.
+	parallelism int
+	// extensions are the only file extensions considered. If nil,
+	// they are the defaultExtensions; if empty, all are considered.
+	extensions map[string]bool
+	logger     Logger
+	slogger    StructuredLogger
+	tracer     Tracer
+	stats      stats
+	events     Events
+
+	signatureKey ed25519.PublicKey
+	// decryptionKey decrypts .enc files; keyErr is the error reading
+	// it from the environment.
+	decryptionKey []byte
+	keyErr        error
+	dependencies  bool
+	numbering     NumberingPolicy
+	downs         downPolicy
+	limits        Limits
+	cache         *bodyCache
+	normalized    bool
+
+	preprocessors []Preprocessor
+
+	baseline     uint
+	baselineFile string
+
+	group  string
+	groups map[string]bool
+
+	archive string
+
+	// scheme, defaults and resolve configure Open for drivers
+	// registered with Register and RegisterFS.
+	scheme   string
+	defaults []Option
+	resolve  func(path string) (Box, error)
+
+	manifest     Manifest
+	manifestFile string
+
+	// decompressors maps file extensions to decompressors. It is nil
+	// until an option changes the defaultDecompressors.
+	decompressors map[string]func(r io.Reader) (io.Reader, error)
+
+	templateData map[string]interface{}
+
+	extraBoxes []interface{}
+	overlay    string
+
+	// minVersion and maxVersion, if limitMax is set, bound the
+	// versions the driver exposes.
+	minVersion uint
+	maxVersion uint
+	limitMax   bool
+	skip       map[uint]bool
+	// versionMap maps historical versions to file versions.
+	versionMap map[uint]uint
+
+	seeds bool
+	// deep and syntax configure the checks of WithDeepValidation.
+	deep   bool
+	syntax SyntaxChecker
+	// env is the environment selected with WithEnvironment.
+	env string
+
+	// reloadEvery is the hot reload interval, or 0 if disabled.
+	// stop ends the watch started when the driver is created.
+	reloadEvery time.Duration
+	stop        chan struct{}
+	stopOnce    sync.Once
 }
 
-// ErrNoBox indicates that a source is not a Packr box instance.
-var ErrNoBox = fmt.Errorf("not a box")
+// WithInstance returns a new driver from a box, which is either
+// a packr v1 Box, any value implementing Box, such as a packr v2 box,
+// a Backend, an fs.FS or an http.FileSystem (see WithHTTPFileSystem).
+// The files of packr v1 boxes are read into memory; to stream very
+// large migrations from a directory on disk, use WithFS(os.DirFS(dir)).
+func WithInstance(box interface{}, opts ...Option) (*Driver, error) {
+	b, err := asBox(box)
+	if err != nil {
+		return nil, err
+	}
+	return newBoxDriver(b, opts)
+}
 
-type packrDriver struct {
-	box        packr.Box
-	migrations *source.Migrations
+func newBoxDriver(box Box, opts []Option) (*Driver, error) {
+	p := &Driver{migrations: newMigrations()}
+	p.apply(opts)
+	if p.archive != "" {
+		archive, err := openArchive(box, p.archive)
+		if err != nil {
+			return nil, err
+		}
+		box = archive
+	}
+	if err := p.checkGroups(box); err != nil {
+		return nil, err
+	}
+	if p.overlay != "" {
+		overlaid, err := newOverlayBox(box, p.overlay, p.dir())
+		if err != nil {
+			return nil, err
+		}
+		box = overlaid
+	}
+	return p.prepare(boxOrigin(p.folded(box), p.dir()))
 }
 
-// WithInstance returns a new driver from a box.
-func WithInstance(box interface{}) (source.Driver, error) {
-	b, ok := box.(packr.Box)
-	if !ok {
+// WithFS returns a new driver reading migrations from fsys,
+// for example an embed.FS.
+func WithFS(fsys fs.FS, opts ...Option) (*Driver, error) {
+	if fsys == nil {
 		return nil, ErrNoBox
 	}
-	p := &packrDriver{box: b, migrations: source.NewMigrations()}
-	if err := p.prepare(); err != nil {
-		return nil, err
+	return newBoxDriver(fsBox{fsys}, opts)
+}
+
+// WithFiles returns a new driver reading migrations from files, which
+// maps file names like db/1_init.up.sql to their contents, so tests can
+// use in-memory fixtures without running the packr code generator.
+// The map is copied, so adding or removing files afterwards doesn't
+// affect the driver, but the contents are served without copying them:
+// callers must not modify the slices once they are passed in.
+func WithFiles(files map[string][]byte, opts ...Option) (*Driver, error) {
+	box := make(filesBox, len(files))
+	for name, data := range files {
+		box[cleanPath(name)] = data
 	}
-	return p, nil
+	return newBoxDriver(box, opts)
+}
+
+// WithBackend returns a new driver reading migrations from b.
+func WithBackend(b Backend, opts ...Option) (*Driver, error) {
+	if b == nil {
+		return nil, ErrNoBox
+	}
+	return newBoxDriver(backendBox{b}, opts)
+}
+
+// WithHTTPFileSystem returns a new driver reading migrations from hfs,
+// for example the file system of assets embedded with statik. Its files
+// are found by walking the directories from the root, so hfs must
+// support listing directories with Readdir.
+func WithHTTPFileSystem(hfs http.FileSystem, opts ...Option) (*Driver, error) {
+	if hfs == nil {
+		return nil, ErrNoBox
+	}
+	return newBoxDriver(httpFSBox{hfs}, opts)
+}
+
+// WithAssetFunc returns a new driver reading migrations from assets
+// embedded with go-bindata, given its generated AssetNames and Asset
+// functions. Asset names are file names like migrations/1_init.up.sql,
+// so WithRoot selects the directory the migrations were embedded from.
+func WithAssetFunc(assetNames func() []string, asset func(name string) ([]byte, error), opts ...Option) (*Driver, error) {
+	if assetNames == nil || asset == nil {
+		return nil, ErrNoBox
+	}
+	return newBoxDriver(assetBox{names: assetNames, asset: asset}, opts)
+}
+
+// WithMigrations returns a new driver serving a pre-parsed set of migrations.
+// No box is involved: the bodies are obtained by calling opener with the
+// Raw field of the requested migration.
+func WithMigrations(migs []source.Migration, opener func(raw string) (io.ReadCloser, error), opts ...Option) (*Driver, error) {
+	if opener == nil {
+		return nil, fmt.Errorf("no opener given")
+	}
+	p := &Driver{migrations: newMigrations()}
+	p.apply(opts)
+	fixed := origin{open: opener}
+	for i := range migs {
+		m := migs[i]
+		fixed.migs = append(fixed.migs, &m)
+	}
+	return p.prepare(fixed)
 }
 
 // Open returns a a new driver instance configured with parameters
 // coming from the URL string.
-func (d *packrDriver) Open(url string) (source.Driver, error) {
+//
+// The URL has the form packr://path/to/box?root=dir where the path
+// is the box and the optional query parameters are:
+//
+//	root      the directory inside the box holding the migrations (see WithRoot)
+//	group     the group directory in root to serve (see WithGroup)
+//	groups    comma separated known groups, like postgres,clickhouse (see WithKnownGroups)
+//	dir       a subdirectory of the group or root to scope the driver to (see WithSubdir)
+//	strict    true to fail on files that aren't migrations (see WithStrictParsing)
+//	manifest  a checksum manifest to verify the migrations against (see WithManifestFile)
+//	overlay   a local directory shadowing the box (see WithOverlay)
+//	archive   a zip or tar archive in the box holding the migrations (see WithArchive)
+//	min       the lowest version to expose (see WithMinVersion)
+//	max       the highest version to expose (see WithMaxVersion)
+//	baseline  a version squashing all migrations up to it (see WithBaseline)
+//	seeds     true to serve the seed migrations instead (see WithSeeds)
+//	env       the environment to serve the migrations of, like prod (see WithEnvironment)
+//	ext       comma separated extensions to consider, like sql,cql (see WithExtensions)
+//	skip      comma separated versions to leave out (see WithSkipVersions)
+//	remap     comma separated historical:file version pairs, like 3:1003 (see WithVersionMap)
+//	reload    an interval to poll the box for changes, like 2s (see WithHotReload)
+//	numbering the version convention to enforce: sequence, unix or datetime (see WithNumberingPolicy)
+//	requires  true to check the dependencies declared by migrations (see WithDependencies)
+//	downs     required, required below a version like required:1000, or forbidden (see WithRequiredDowns and WithoutDowns)
+//	maxbytes  the largest migration body allowed (see WithLimits)
+//	maxstmts  the most statements a migration body may hold (see WithLimits)
+//	forbid    comma separated statements not allowed, like DROP DATABASE,TRUNCATE (see WithLimits)
+//	deep      true to check every body is non-empty UTF-8, or sql to also check it with CheckSQL (see WithDeepValidation)
+//	keyenv    the environment variable holding the key of encrypted files (see WithDecryptionKeyEnv)
+//	idcheck   true to fail on up and down migrations with different identifiers (see WithIdentifierCheck)
+//	parallel  the number of workers parsing the box, like 8 (see WithParallelism)
+func (d *Driver) Open(url string) (source.Driver, error) {
 	if url == "" {
 		return nil, fmt.Errorf("invalid URL '%s'", url)
 	}
-	box := packr.NewBox(url)
-	p := &packrDriver{
-		migrations: source.NewMigrations(),
-		box:        box,
+	boxPath, query, err := parseURL(url, d.urlScheme())
+	if err != nil {
+		return nil, err
+	}
+	box, err := d.resolveBox(boxPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL '%s': %v", url, err)
 	}
 
-	if err := p.prepare(); err != nil {
+	opts, err := queryOptions(query)
+	if err != nil {
 		return nil, err
 	}
+	return newBoxDriver(box, append(d.defaults[:len(d.defaults):len(d.defaults)], opts...))
+}
 
-	return p, nil
+// dir returns the directory inside the box the migrations are read from.
+// The group directory is always relative to the root, the subdirectory
+// relative to both, and the seeds directory relative to all of them.
+func (d *Driver) dir() string {
+	if d.seeds {
+		return path.Join(d.root, d.group, d.subdir, seedsDir)
+	}
+	return path.Join(d.root, d.group, d.subdir)
 }
 
-// Close closes the underlying source instance managed by the driver.
-// Since packr boxes don't close, this function doesn't do anything.
-func (d *packrDriver) Close() error {
-	// nothing to close
+// parseURL splits a URL of scheme into the box path and its query parameters.
+// Everything between the scheme and the query is the path, so
+// packr://migrations and packr://./migrations are relative and
+// packr:///srv/app/migrations is absolute. Windows paths may be given
+// as packr://C:\app\migrations or packr:///C:/app/migrations.
+// Percent-encoded characters in the path are decoded; a path that isn't
+// validly encoded, like one with a literal %, is used as is.
+func parseURL(url, scheme string) (string, nurl.Values, error) {
+	rest := url
+	if prefix := scheme + "://"; len(rest) >= len(prefix) && strings.EqualFold(rest[:len(prefix)], prefix) {
+		rest = rest[len(prefix):]
+	}
+	rawQuery := ""
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		rest, rawQuery = rest[:i], rest[i+1:]
+	}
+	query, err := nurl.ParseQuery(rawQuery)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid URL query '%s': %v", rawQuery, err)
+	}
+	if decoded, err := nurl.PathUnescape(rest); err == nil {
+		rest = decoded
+	}
+	if strings.HasPrefix(rest, "/") && isWindowsDrive(rest[1:]) {
+		rest = rest[1:]
+	}
+	return rest, query, nil
+}
+
+// isWindowsDrive reports whether p starts with a drive letter, like C:.
+func isWindowsDrive(p string) bool {
+	if len(p) < 2 || p[1] != ':' {
+		return false
+	}
+	c := p[0]
+	return ('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') && (len(p) == 2 || p[2] == '/' || p[2] == '\\')
+}
+
+// Close stops watching for changes if hot reload is enabled and
+// releases the index and the boxes of the driver, so that data like
+// the files given to WithFiles can be freed. Bodies that were already
+// returned can still be read and must be closed as usual. Afterwards,
+// all other methods fail with ErrClosed. Closing a closed driver does
+// nothing.
+func (d *Driver) Close() error {
+	d.stopWatch()
+	d.build.Lock()
+	defer d.build.Unlock()
+	d.mu.Lock()
+	d.migrations, d.size = newMigrations(), nil
+	d.open = func(raw string) (io.ReadCloser, error) { return nil, ErrClosed }
+	d.repeatables, d.baselineParts, d.fingerprint = nil, nil, ""
+	d.sums, d.closed = nil, true
+	d.mu.Unlock()
+	d.cache.reset()
+	d.origins = nil
+	return nil
+}
+
+// alive returns ErrClosed if the driver was closed.
+func (d *Driver) alive() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
 	return nil
 }
 
 // First returns the very first migration version available to the driver.
-// If there is no version available, it returns os.ErrNotExist.
-func (d *packrDriver) First() (version uint, err error) {
+// If there is no version available, it returns ErrNoMigrations.
+func (d *Driver) First() (version uint, err error) {
+	d.mu.RLock()
 	v, ok := d.migrations.First()
+	closed := d.closed
+	d.mu.RUnlock()
+	if closed {
+		return 0, ErrClosed
+	}
 	if ok {
 		return v, nil
 	}
-	return 0, os.ErrNotExist
+	return 0, ErrNoMigrations
 }
 
 // Prev returns the previous version for a given version available to the driver.
 // If there is no previous version available, it returns os.ErrNotExist.
-func (d *packrDriver) Prev(version uint) (prevVersion uint, err error) {
+func (d *Driver) Prev(version uint) (prevVersion uint, err error) {
+	d.mu.RLock()
 	index, ok := d.migrations.Prev(version)
+	closed := d.closed
+	d.mu.RUnlock()
+	if closed {
+		return 0, ErrClosed
+	}
 	if ok {
 		return index, nil
 	}
@@ -85,8 +427,14 @@ func (d *packrDriver) Prev(version uint) (prevVersion uint, err error) {
 
 // Next returns the next version for a given version available to the driver.
 // If there is no next version available, it returns os.ErrNotExist.
-func (d *packrDriver) Next(version uint) (nextVersion uint, err error) {
+func (d *Driver) Next(version uint) (nextVersion uint, err error) {
+	d.mu.RLock()
 	index, ok := d.migrations.Next(version)
+	closed := d.closed
+	d.mu.RUnlock()
+	if closed {
+		return 0, ErrClosed
+	}
 	if ok {
 		return index, nil
 	}
@@ -96,48 +444,274 @@ func (d *packrDriver) Next(version uint) (nextVersion uint, err error) {
 // ReadUp returns the UP migration body and an identifier that helps
 // finding this migration in the source for a given version.
 // If there is no up migration available for this version,
-// it returns os.ErrNotExist.
-func (d *packrDriver) ReadUp(version uint) (r io.ReadCloser, identifier string, err error) {
-	m, ok := d.migrations.Up(version)
-	if !ok {
-		return nil, "", os.ErrNotExist
-	}
-
-	data, err := d.box.Find(m.Raw)
-	if err != nil {
-		return nil, "", os.ErrExist
-	}
-	return ioutil.NopCloser(bytes.NewReader(data)), m.Identifier, nil
+// it returns os.ErrNotExist. If the file can't be opened,
+// the error is an *ErrOpenFailed.
+func (d *Driver) ReadUp(version uint) (r io.ReadCloser, identifier string, err error) {
+	return d.ReadUpContext(context.Background(), version)
 }
 
 // ReadDown returns the DOWN migration body and an identifier that helps
 // finding this migration in the source for a given version.
 // If there is no down migration available for this version,
-// it returns os.ErrNotExist.
-func (d *packrDriver) ReadDown(version uint) (r io.ReadCloser, identifier string, err error) {
-	m, ok := d.migrations.Down(version)
-	if !ok {
-		return nil, "", os.ErrNotExist
+// it returns os.ErrNotExist. If the file can't be opened,
+// the error is an *ErrOpenFailed.
+func (d *Driver) ReadDown(version uint) (r io.ReadCloser, identifier string, err error) {
+	return d.ReadDownContext(context.Background(), version)
+}
+
+// read opens the body of m, waiting for a free reader slot if
+// the number of concurrent reads is limited.
+func (d *Driver) read(ctx context.Context, m *source.Migration) (io.ReadCloser, error) {
+	if d.tracer != nil {
+		var span Span
+		ctx, span = d.startSpan(ctx, "packr.read", d.readAttributes(m)...)
+		r, err := d.readSlot(ctx, m)
+		if err != nil {
+			endSpan(span, err)
+			return nil, err
+		}
+		return &spanReader{ReadCloser: r, span: span}, nil
 	}
-	data, err := d.box.Find(m.Raw)
+	return d.readSlot(ctx, m)
+}
+
+// readSlot is read without tracing.
+func (d *Driver) readSlot(ctx context.Context, m *source.Migration) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	release := func() {}
+	if d.readers != nil {
+		select {
+		case d.readers <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-d.readers }
+	}
+	r, err := d.body(m)
 	if err != nil {
-		return nil, "", os.ErrExist
+		release()
+		return nil, err
+	}
+	if ctx.Done() != nil {
+		r = &ctxReader{ReadCloser: r, ctx: ctx}
 	}
-	return ioutil.NopCloser(bytes.NewReader(data)), m.Identifier, nil
+	r = &countingReader{ReadCloser: r, d: d}
+	if d.readers != nil {
+		r = &slotReader{ReadCloser: r, release: release}
+	}
+	d.served(m)
+	d.countRead(m)
+	d.readEvent(m)
+	return r, nil
 }
 
-func (d *packrDriver) prepare() error {
-	files := d.box.List()
-	sort.Strings(files)
+// body returns the body of m: the generated or configured baseline
+// for the baseline migration (see WithBaseline), and the stored file
+// otherwise, from the cache if WithBodyCache is used.
+func (d *Driver) body(m *source.Migration) (io.ReadCloser, error) {
+	if d.cache != nil {
+		return d.cache.body(m, d.load, d.readAll)
+	}
+	return d.load(m)
+}
+
+// load is body without the cache.
+func (d *Driver) load(m *source.Migration) (io.ReadCloser, error) {
+	return d.loadFrom(d.current(), m)
+}
 
-	for _, file := range files {
-		m, err := source.DefaultParse(file)
+// loadFrom is load reading the files of idx, which need not be the
+// index of the driver yet.
+func (d *Driver) loadFrom(idx *index, m *source.Migration) (io.ReadCloser, error) {
+	if d.isBaseline(m) {
+		return d.baselineBody(idx, m)
+	}
+	return d.stored(idx.open, m)
+}
+
+// stored opens the file of m and runs it through the configured
+// transformations, in this order:
+//
+//	verify      check the signature of the stored file (WithSignatureKey)
+//	decrypt     decrypt encrypted files (WithDecryptionKey)
+//	decompress  decompress compressed files (WithDecompressor)
+//	decode      transcode to UTF-8 (WithEncodingSniffer)
+//	normalize   strip the BOM and normalize line endings (WithNormalization)
+//	render      render templates (WithTemplateData)
+//	splitGoose  select the section for m's direction (WithGooseFormat)
+//	preprocess  run the preprocessors (WithPreprocessor)
+//
+// Each step passes r through unchanged if it isn't configured. A step
+// that fails leaves closing r to stored. Files, such as the signature
+// of m, are opened with open.
+func (d *Driver) stored(open func(raw string) (io.ReadCloser, error), m *source.Migration) (io.ReadCloser, error) {
+	r, err := open(m.Raw)
+	if err != nil {
+		d.openFailed(m.Raw, err)
+		return nil, &ErrOpenFailed{File: m.Raw, Err: err}
+	}
+	steps := []func(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error){
+		func(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error) {
+			return d.verify(open, m, r)
+		},
+		d.decrypt,
+		d.decompress,
+		d.decode,
+		d.normalize,
+		d.render,
+		d.splitGoose,
+		d.preprocess,
+	}
+	for _, step := range steps {
+		next, err := step(m, r)
 		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		r = next
+	}
+	return r, nil
+}
+
+// prepare builds the index of a new driver from o and the boxes
+// configured with WithBoxes, and runs the checks.
+func (d *Driver) prepare(o origin) (*Driver, error) {
+	origins, err := d.withBoxes(o)
+	if err != nil {
+		return nil, err
+	}
+	d.build.Lock()
+	err = d.rebuild(origins)
+	d.build.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	d.startWatch()
+	return d, nil
+}
+
+// check runs the checks configured to happen when the driver is created
+// on idx, after it has been built and before it becomes the index of
+// the driver.
+func (d *Driver) check(idx *index) error {
+	if err := d.checkEncryption(idx); err != nil {
+		return err
+	}
+	if err := d.checkNumbering(idx); err != nil {
+		return err
+	}
+	if err := d.checkDowns(idx); err != nil {
+		return err
+	}
+	if err := d.verifyManifest(idx); err != nil {
+		return err
+	}
+	if err := d.verifySignatures(idx); err != nil {
+		return err
+	}
+	if err := d.verifyDependencies(idx); err != nil {
+		return err
+	}
+	if err := d.checkLimits(idx); err != nil {
+		return err
+	}
+	return d.checkBodies(idx)
+}
+
+// parseBox returns the migrations found in the directory root of box,
+// in file name order, and the repeatable migrations among them.
+// The Raw field of each migration is relative to root.
+// Files that can't be parsed are skipped unless the driver is strict,
+// in which case the error lists all of them and unwraps to the first
+// parse error. Skipped files are passed to skip with the reason.
+//
+// Boxes may hold thousands of other assets, so files are filtered
+// before anything else is done with them, and only the migrations
+// found are sorted.
+func (d *Driver) parseBox(box Box, root string, skip func(file string, reason interface{})) ([]*source.Migration, []*source.Migration, error) {
+	var migs, repeatables []*source.Migration
+	var failed []string
+	var cause error
+	fail := func(file string, err error) {
+		skip(file, err)
+		d.countParseFailure()
+		if !d.strict {
+			return
+		}
+		failed = append(failed, fmt.Sprintf("%s (%v)", file, err))
+		if cause == nil {
+			cause = err
+		}
+	}
+	manifest := ""
+	if d.manifestFile != "" {
+		manifest = cleanPath(d.manifestFile)
+	}
+	files := listDir(box, root)
+	results := make([]parsed, len(files))
+	d.parallel(len(files), func(i int) {
+		results[i] = d.parseFile(box, root, manifest, files[i])
+	})
+	for i, r := range results {
+		switch {
+		case r.ignored:
+		case r.skip != nil:
+			skip(files[i], r.skip)
+		case r.err != nil:
+			fail(files[i], r.err)
+		case r.repeatable != nil:
+			repeatables = append(repeatables, r.repeatable)
+		default:
+			migs = append(migs, r.migs...)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return nil, nil, &wrapped{"unable to parse migrations: " + strings.Join(failed, ", "), cause}
+	}
+	sort.SliceStable(migs, func(i, j int) bool { return migs[i].Raw < migs[j].Raw })
+	return migs, repeatables, nil
+}
+
+// PeekLatestVersion returns the highest migration version in box
+// without building a driver. The box is accepted in the same forms as
+// by WithInstance. Files are selected like the index of a driver
+// created with opts would, so options like WithRoot, WithExtensions,
+// WithParser, WithVersionMap and WithMaxVersion are taken into account,
+// and the version returned is the one the driver would serve. No file is
+// read unless WithGooseFormat is given.
+// If the box holds no migrations, it returns ErrNoMigrations.
+func PeekLatestVersion(box interface{}, opts ...Option) (uint, error) {
+	b, err := asBox(box)
+	if err != nil {
+		return 0, err
+	}
+	d := &Driver{}
+	d.apply(opts)
+	inverse, err := d.historical()
+	if err != nil {
+		return 0, err
+	}
+	migs, _, err := d.parseBox(newListedBox(d.folded(b)), d.dir(), func(string, interface{}) {})
+	if err != nil {
+		return 0, err
+	}
+	var latest uint
+	found := false
+	for _, m := range migs {
+		m = remapped(inverse, m)
+		if d.skip[m.Version] || !d.inRange(m) {
 			continue
 		}
-		if !d.migrations.Append(m) {
-			return fmt.Errorf("unable to parse migration: %s", file)
+		if !found || m.Version > latest {
+			latest = m.Version
+			found = true
 		}
 	}
-	return nil
+	if !found {
+		return 0, ErrNoMigrations
+	}
+	return latest, nil
 }
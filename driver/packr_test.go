@@ -1,11 +1,16 @@
 package driver
 
 import (
+	"errors"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
+	"github.com/gobuffalo/packr"
+	"github.com/golang-migrate/migrate/v4/source"
 	st "github.com/golang-migrate/migrate/v4/source/testing"
 )
 
@@ -30,7 +35,7 @@ func Test(t *testing.T) {
 	mustWriteFile(t, tmpDir, "7_foobar.up.sql", "7 up")
 	mustWriteFile(t, tmpDir, "7_foobar.down.sql", "7 down")
 
-	p := &packrDriver{}
+	p := &Driver{}
 	d, err := p.Open(tmpDir)
 	if err != nil {
 		t.Fatal(err)
@@ -38,8 +43,392 @@ func Test(t *testing.T) {
 	st.Test(t, d)
 }
 
+func TestOpenWithRoot(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	schemaDir := path.Join(tmpDir, "db", "schema")
+	if err := os.MkdirAll(schemaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, tmpDir, "9_outside.up.sql", "outside")
+	mustWriteFile(t, schemaDir, "1_foobar.up.sql", "1 up")
+	mustWriteFile(t, schemaDir, "1_foobar.down.sql", "1 down")
+	mustWriteFile(t, schemaDir, "3_foobar.up.sql", "3 up")
+	mustWriteFile(t, schemaDir, "4_foobar.up.sql", "4 up")
+	mustWriteFile(t, schemaDir, "4_foobar.down.sql", "4 down")
+	mustWriteFile(t, schemaDir, "5_foobar.down.sql", "5 down")
+	mustWriteFile(t, schemaDir, "7_foobar.up.sql", "7 up")
+	mustWriteFile(t, schemaDir, "7_foobar.down.sql", "7 down")
+
+	p := &Driver{}
+	d, err := p.Open("packr://" + tmpDir + "?root=db/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Test(t, d)
+}
+
+func TestWithRoot(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"db/schema/1_init.up.sql":  "init",
+		"db/schema/sub/2_x.up.sql": "nested",
+		"db/3_above.up.sql":        "above",
+	})
+	d, err := WithInstance(box, WithRoot("db/schema/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, identifier, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	body, _ := ioutil.ReadAll(r)
+	if identifier != "init" || string(body) != "init" {
+		t.Errorf("unexpected migration %q: %q", identifier, body)
+	}
+	if _, err := d.Next(1); err == nil {
+		t.Error("expected only the migration directly inside root")
+	}
+}
+
+func TestWithRootSegmentBoundary(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"postgres/1_init.up.sql":         "postgres",
+		"postgresql-old/2_legacy.up.sql": "legacy",
+		"postgres-extra/3_extra.up.sql":  "extra",
+		"other/postgres/4_nested.up.sql": "nested",
+		"postgres/sub/5_too_deep.up.sql": "deep",
+		"postgres1_not_a_dir.up.sql":     "root",
+	})
+	for _, root := range []string{"postgres", "postgres/", "./postgres", "/postgres"} {
+		d, err := WithInstance(box, WithRoot(root))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := d.First()
+		if err != nil || v != 1 {
+			t.Errorf("root %q: expected first version 1, got %d, %v", root, v, err)
+		}
+		if _, err := d.Next(1); err == nil {
+			t.Errorf("root %q: expected only postgres/1_init.up.sql to match", root)
+		}
+	}
+}
+
+func TestOpenWithDir(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, dir := range []string{"assets/db/postgres", "assets/db/mysql"} {
+		if err := os.MkdirAll(path.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustWriteFile(t, path.Join(tmpDir, "assets/db/postgres"), "1_pg.up.sql", "pg")
+	mustWriteFile(t, path.Join(tmpDir, "assets/db/mysql"), "2_my.up.sql", "my")
+
+	p := &Driver{}
+	for url, want := range map[string]uint{
+		"packr://" + tmpDir + "?dir=assets/db/postgres":        1,
+		"packr://" + tmpDir + "?root=assets/db&dir=mysql":      2,
+		"packr://" + tmpDir + "?dir=mysql&root=assets/db/":     2,
+		"packr://" + tmpDir + "/assets?root=db&dir=./postgres": 1,
+	} {
+		d, err := p.Open(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, err := d.First(); err != nil || v != want {
+			t.Errorf("%s: expected first version %d, got %d, %v", url, want, v, err)
+		}
+		if _, err := d.Next(want); err == nil {
+			t.Errorf("%s: expected a single migration", url)
+		}
+	}
+}
+
+func TestWithSubdir(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"assets/db/postgres/1_init.up.sql": "init",
+		"assets/db/1_other.up.sql":         "other",
+		"assets/index.html":                "",
+	})
+	d, err := WithInstance(box, WithSubdir("assets/db/postgres"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, identifier, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if identifier != "init" {
+		t.Errorf("expected init, got %s", identifier)
+	}
+}
+
+func TestInDir(t *testing.T) {
+	tt := []struct {
+		name, dir string
+		rel       string
+		ok        bool
+	}{
+		{name: "1_a.up.sql", dir: "", rel: "1_a.up.sql", ok: true},
+		{name: "1_a.up.sql", dir: ".", rel: "1_a.up.sql", ok: true},
+		{name: "postgres/1_a.up.sql", dir: "postgres", rel: "1_a.up.sql", ok: true},
+		{name: "postgres/1_a.up.sql", dir: "postgres/", rel: "1_a.up.sql", ok: true},
+		{name: "./postgres/1_a.up.sql", dir: "postgres", rel: "1_a.up.sql", ok: true},
+		{name: "/postgres/1_a.up.sql", dir: "./postgres", rel: "1_a.up.sql", ok: true},
+		{name: "db/postgres/1_a.up.sql", dir: "db/postgres", rel: "1_a.up.sql", ok: true},
+		{name: "postgresql-old/1_a.up.sql", dir: "postgres", ok: false},
+		{name: "postgres1_a.up.sql", dir: "postgres", ok: false},
+		{name: "postgres", dir: "postgres", ok: false},
+		{name: "db/postgres/1_a.up.sql", dir: "postgres", ok: false},
+		{name: "postgres/1_a.up.sql", dir: "postgres/1", ok: false},
+	}
+	for _, tc := range tt {
+		rel, ok := inDir(tc.name, tc.dir)
+		if ok != tc.ok || rel != tc.rel {
+			t.Errorf("inDir(%q, %q): expected %q, %v, got %q, %v", tc.name, tc.dir, tc.rel, tc.ok, rel, ok)
+		}
+	}
+}
+
+func TestOpenInvalidQuery(t *testing.T) {
+	p := &Driver{}
+	if _, err := p.Open("packr://migrations?root=%zz"); err == nil {
+		t.Fatal("expected error for malformed query")
+	}
+	if _, err := p.Open("packr://migrations?strict=maybe"); err == nil {
+		t.Fatal("expected error for invalid strict value")
+	}
+}
+
+func TestOpenStrict(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	mustWriteFile(t, tmpDir, "1_init.up.sql", "")
+	mustWriteFile(t, tmpDir, "2_init.sql", "")
+
+	p := &Driver{}
+	if _, err := p.Open("packr://" + tmpDir + "?strict=false"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Open("packr://" + tmpDir + "?strict=true"); err == nil {
+		t.Fatal("expected strict mode to reject 2_init.sql")
+	}
+}
+
+func TestPeekLatestVersion(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_a.up.sql":     "",
+		"12_b.down.sql":  "",
+		"3_c.up.sql":     "",
+		"99_notes.md":    "",
+		"100-bad.up.sql": "",
+	})
+	v, err := PeekLatestVersion(box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 12 {
+		t.Errorf("expected 12, got %d", v)
+	}
+
+	box = newTestBox(map[string]string{
+		"db/2_a.up.sql":    "",
+		"db/9_notes.up.md": "",
+		"7_other.up.sql":   "",
+	})
+	if v, err := PeekLatestVersion(box, WithRoot("db")); err != nil || v != 2 {
+		t.Errorf("expected 2 from the root, ignoring other extensions, got %d, %v", v, err)
+	}
+	if v, err := PeekLatestVersion(box, WithRoot("db"), WithExtensions(".md")); err != nil || v != 9 {
+		t.Errorf("expected 9 with the extensions given, got %d, %v", v, err)
+	}
+
+	box = newTestBox(map[string]string{
+		"1_a.up.sql":    "",
+		"1003_b.up.sql": "",
+	})
+	remap := WithVersionMap(map[uint]uint{3: 1003})
+	if v, err := PeekLatestVersion(box, remap); err != nil || v != 3 {
+		t.Errorf("expected the historical version 3, got %d, %v", v, err)
+	}
+	if v, err := PeekLatestVersion(box, remap, WithMaxVersion(10)); err != nil || v != 3 {
+		t.Errorf("expected the range to apply to the historical version, got %d, %v", v, err)
+	}
+
+	_, err = PeekLatestVersion(newTestBox(map[string]string{"README.md": ""}))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestWithMigrations(t *testing.T) {
+	bodies := map[string]string{
+		"1_foobar.up.sql":   "1 up",
+		"1_foobar.down.sql": "1 down",
+		"3_foobar.up.sql":   "3 up",
+		"4_foobar.up.sql":   "4 up",
+		"4_foobar.down.sql": "4 down",
+		"5_foobar.down.sql": "5 down",
+		"7_foobar.up.sql":   "7 up",
+		"7_foobar.down.sql": "7 down",
+	}
+	var migs []source.Migration
+	for name := range bodies {
+		m, err := source.DefaultParse(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		migs = append(migs, *m)
+	}
+	opener := func(raw string) (io.ReadCloser, error) {
+		body, ok := bodies[raw]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return ioutil.NopCloser(strings.NewReader(body)), nil
+	}
+
+	d, err := WithMigrations(migs, opener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Test(t, d)
+}
+
+func TestWithMigrationsDuplicate(t *testing.T) {
+	migs := []source.Migration{
+		{Version: 1, Identifier: "a", Direction: source.Up, Raw: "1_a.up.sql"},
+		{Version: 1, Identifier: "b", Direction: source.Up, Raw: "1_b.up.sql"},
+	}
+	opener := func(raw string) (io.ReadCloser, error) { return nil, os.ErrNotExist }
+	if _, err := WithMigrations(migs, opener); err == nil {
+		t.Fatal("expected error for duplicate migration")
+	}
+	if _, err := WithMigrations(migs[:1], nil); err == nil {
+		t.Fatal("expected error for missing opener")
+	}
+}
+
+func newTestDriver(t *testing.T, bodies map[string]string, opts ...Option) *Driver {
+	t.Helper()
+	var migs []source.Migration
+	for name := range bodies {
+		m, err := source.DefaultParse(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		migs = append(migs, *m)
+	}
+	opener := func(raw string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(bodies[raw])), nil
+	}
+	d, err := WithMigrations(migs, opener, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func newTestBox(files map[string]string) packr.Box {
+	box := packr.NewBox("./testdata/none")
+	for name, body := range files {
+		box.AddString(name, body)
+	}
+	return box
+}
+
+func readUp(d *Driver) func(uint) (string, error) {
+	return func(version uint) (string, error) {
+		r, _, err := d.ReadUp(version)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		body, err := ioutil.ReadAll(r)
+		return string(body), err
+	}
+}
+
+func readDown(d *Driver) func(uint) (string, error) {
+	return func(version uint) (string, error) {
+		r, _, err := d.ReadDown(version)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		body, err := ioutil.ReadAll(r)
+		return string(body), err
+	}
+}
+
 func mustWriteFile(t testing.TB, dir, file string, body string) {
 	if err := ioutil.WriteFile(path.Join(dir, file), []byte(body), 06444); err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestParseURL(t *testing.T) {
+	for _, test := range []struct {
+		url   string
+		path  string
+		query string
+	}{
+		{"packr://migrations", "migrations", ""},
+		{"packr://./db/migrations?root=schema", "./db/migrations", "schema"},
+		{"packr://../migrations", "../migrations", ""},
+		{"packr:///srv/app/migrations?root=schema", "/srv/app/migrations", "schema"},
+		{"packr://host/migrations", "host/migrations", ""},
+		{"PACKR://migrations", "migrations", ""},
+		{"/srv/app/migrations", "/srv/app/migrations", ""},
+		{`packr://C:\app\migrations?root=schema`, `C:\app\migrations`, "schema"},
+		{"packr://C:/app/migrations", "C:/app/migrations", ""},
+		{"packr:///C:/app/migrations", "C:/app/migrations", ""},
+		{"packr:///c:", "c:", ""},
+		{"packr:///my%20app/migrations", "/my app/migrations", ""},
+		{"packr://my app/migrations", "my app/migrations", ""},
+		{"packr://100%25/migrations?root=a%2Fb", "100%/migrations", "a/b"},
+		{"packr://100%/migrations", "100%/migrations", ""},
+		{"packr://what%3F/migrations", "what?/migrations", ""},
+	} {
+		path, query, err := parseURL(test.url, "packr")
+		if err != nil {
+			t.Errorf("%s: %v", test.url, err)
+			continue
+		}
+		if path != test.path || query.Get("root") != test.query {
+			t.Errorf("%s: expected %q and root %q, got %q and %q", test.url, test.path, test.query, path, query.Get("root"))
+		}
+	}
+}
+
+func TestOpenEncodedPath(t *testing.T) {
+	tmpDir := path.Join(t.TempDir(), "my migrations")
+	if err := os.Mkdir(tmpDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, tmpDir, "1_init.up.sql", "init")
+
+	d, err := (&Driver{}).Open("packr://" + strings.ReplaceAll(tmpDir, " ", "%20"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := readUp(d.(*Driver))(1)
+	if err != nil || body != "init" {
+		t.Errorf("expected the migration in %s, got %q, %v", tmpDir, body, err)
+	}
+}
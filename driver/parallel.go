@@ -0,0 +1,129 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// WithParallelism makes the driver parse the files of a box and compute
+// the checksums of their migrations with up to n workers when the index
+// is built, which pays off for boxes embedding thousands of assets.
+// The files are split into n contiguous parts and the results merged in
+// the order the box lists them, so the index, skipped files and errors
+// don't depend on n. A parser given to WithParser and a Sanitizer must
+// be safe for concurrent use. A value of one or less parses
+// sequentially, which is the default.
+func WithParallelism(n int) Option {
+	return func(d *Driver) {
+		d.parallelism = n
+	}
+}
+
+// parallel calls work for every index below count, with the indexes
+// split into contiguous parts worked on by up to d.parallelism
+// goroutines. It returns once all calls returned.
+func (d *Driver) parallel(count int, work func(i int)) {
+	n := d.parallelism
+	if n > count {
+		n = count
+	}
+	if n <= 1 {
+		for i := 0; i < count; i++ {
+			work(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for part := 0; part < n; part++ {
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			for i := from; i < to; i++ {
+				work(i)
+			}
+		}(part*count/n, (part+1)*count/n)
+	}
+	wg.Wait()
+}
+
+// parsed is what parseBox found out about a file.
+type parsed struct {
+	ignored    bool
+	skip       interface{}
+	err        error
+	repeatable *source.Migration
+	migs       []*source.Migration
+}
+
+// parseFile returns what file in the directory root of box is.
+// Files in subdirectories are skipped before their names are parsed,
+// even if the driver is strict, except for the files of environments
+// handled by parseScoped.
+func (d *Driver) parseFile(box Box, root, manifest, file string) parsed {
+	if file == manifest || d.isSeed(file) || d.isSignature(file) || d.isBaselineFile(file) {
+		return parsed{ignored: true}
+	}
+	if !d.allowed(file) {
+		return parsed{skip: "extension not allowed"}
+	}
+	_, scoped := inCleanDir(file, envDir)
+	if strings.Contains(file, "/") && (d.env == "" || !scoped) {
+		return parsed{skip: "in a subdirectory"}
+	}
+	if d.env != "" {
+		return d.sanitized(d.parseScoped(box, root, file))
+	}
+	return d.sanitized(d.parseName(box, root, file))
+}
+
+// parseName returns the migrations named file in the directory root
+// of box.
+func (d *Driver) parseName(box Box, root, file string) parsed {
+	if d.isRepeatable(file) {
+		return parsed{repeatable: d.repeatable(file)}
+	}
+	if d.goose {
+		migs, err := d.parseGoose(box, root, file)
+		return parsed{migs: migs, err: err}
+	}
+	m, err := d.parse(file)
+	if err != nil {
+		return parsed{err: err}
+	}
+	return parsed{migs: []*source.Migration{m}}
+}
+
+// checksums returns the checksums of the files raws, reading them in
+// parallel. Of several unreadable files, the error names the first.
+func (d *Driver) checksums(open func(raw string) (io.ReadCloser, error), raws []string) (Manifest, error) {
+	sums := make([]string, len(raws))
+	errs := make([]error, len(raws))
+	d.parallel(len(raws), func(i int) {
+		sums[i], errs[i] = checksum(open, raws[i])
+	})
+	m := make(Manifest, len(raws))
+	for i, raw := range raws {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("unable to read migration %s: %v", raw, errs[i])
+		}
+		m[raw] = sums[i]
+	}
+	return m, nil
+}
+
+// distinct returns files without repetitions, in their order.
+func distinct(files []string) []string {
+	seen := make(map[string]bool, len(files))
+	var out []string
+	for _, file := range files {
+		if !seen[file] {
+			seen[file] = true
+			out = append(out, file)
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithParallelism(t *testing.T) {
+	box := monolithBox(2000)
+	box["db/5_broken.sql"] = nil
+	box["db/R__views.sql"] = []byte("create view v;")
+	box["db/notes.txt"] = nil
+
+	build := func(n int, opts ...Option) (*Driver, error) {
+		return WithInstance(box, append([]Option{WithRoot("db"), WithParallelism(n)}, opts...)...)
+	}
+	want, err := build(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantList, err := want.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSums, err := want.Checksums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, wantErr := build(1, WithStrictParsing())
+	if wantErr == nil {
+		t.Fatal("expected an error in strict mode")
+	}
+
+	for _, n := range []int{0, 2, 7, 64, 5000} {
+		d, err := build(n)
+		if err != nil {
+			t.Fatalf("%d workers: %v", n, err)
+		}
+		if got, _ := d.List(); !reflect.DeepEqual(got, wantList) {
+			t.Errorf("%d workers: expected the sequential index, got %v", n, got)
+		}
+		if !reflect.DeepEqual(d.Report(), want.Report()) {
+			t.Errorf("%d workers: expected report %+v, got %+v", n, want.Report(), d.Report())
+		}
+		if sums, err := d.Checksums(); err != nil || !reflect.DeepEqual(sums, wantSums) {
+			t.Errorf("%d workers: expected the sequential checksums, got %v", n, err)
+		}
+		if _, err := build(n, WithStrictParsing()); err == nil || err.Error() != wantErr.Error() {
+			t.Errorf("%d workers: expected %v, got %v", n, wantErr, err)
+		}
+	}
+}
+
+func TestParallelismURL(t *testing.T) {
+	if _, err := queryOptions(map[string][]string{"parallel": {"many"}}); err == nil {
+		t.Error("expected an error for an invalid parallel value")
+	}
+	opts, err := queryOptions(map[string][]string{"parallel": {"4"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Driver{}
+	d.apply(opts)
+	if d.parallelism != 4 {
+		t.Errorf("expected 4 workers, got %d", d.parallelism)
+	}
+}
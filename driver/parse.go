@@ -0,0 +1,112 @@
+package driver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// encodedRegex matches the same file names as source.Regex but accepts
+// any version string not containing an underscore, leaving its
+// interpretation to the version encoder.
+var encodedRegex = regexp.MustCompile(`^([^_/]+)_(.*)\.(` + string(source.Down) + `|` + string(source.Up) + `)\.(.*)$`)
+
+// WithParser replaces source.DefaultParse as the function turning file
+// names into migrations. It should return source.ErrParse for files
+// that aren't migrations.
+func WithParser(parse func(raw string) (*source.Migration, error)) Option {
+	return func(d *Driver) {
+		d.parser = parse
+	}
+}
+
+// WithRegex parses file names with re instead of source.Regex.
+// The version, identifier and direction are taken from the subexpressions
+// named "version", "identifier" and "direction", or from the first three
+// subexpressions if re has no named ones. For example
+//
+//	^V([0-9]+)__(.*)\.(up|down)\.sql$
+//
+// indexes V20230101120000__add_users.up.sql as version 20230101120000.
+// The version is interpreted by the version encoder if one is set.
+func WithRegex(re *regexp.Regexp) Option {
+	return func(d *Driver) {
+		d.regex = re
+	}
+}
+
+// parse returns the migration described by the file name raw.
+func (d *Driver) parse(raw string) (*source.Migration, error) {
+	if d.parser != nil {
+		return d.parser(raw)
+	}
+	re := d.regex
+	if re == nil {
+		if d.encodeVersion == nil {
+			// Names of default migrations start with a digit, which
+			// rules out most assets without running the regex.
+			if raw == "" || raw[0] < '0' || raw[0] > '9' {
+				return nil, source.ErrParse
+			}
+			return source.DefaultParse(raw)
+		}
+		re = encodedRegex
+	}
+	return d.parseRegex(re, raw)
+}
+
+// parseRegex parses raw with re, see WithRegex.
+func (d *Driver) parseRegex(re *regexp.Regexp, raw string) (*source.Migration, error) {
+	match := re.FindStringSubmatch(raw)
+	if match == nil {
+		return nil, source.ErrParse
+	}
+	version, identifier, direction := submatch(re, match, "version", 1), submatch(re, match, "identifier", 2), submatch(re, match, "direction", 3)
+
+	v, err := d.version(version)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := source.Direction(direction)
+	if dir != source.Up && dir != source.Down {
+		return nil, fmt.Errorf("invalid direction '%s'", direction)
+	}
+	return &source.Migration{
+		Version:    v,
+		Identifier: identifier,
+		Direction:  dir,
+		Raw:        raw,
+	}, nil
+}
+
+// version interprets the version part of a file name, using the
+// version encoder if one is set.
+func (d *Driver) version(raw string) (uint, error) {
+	if d.encodeVersion != nil {
+		v, err := d.encodeVersion(raw)
+		if err != nil {
+			return 0, fmt.Errorf("invalid version '%s': %v", raw, err)
+		}
+		return v, nil
+	}
+	v, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version '%s': %v", raw, err)
+	}
+	return uint(v), nil
+}
+
+// submatch returns the subexpression called name, falling back to the
+// subexpression at index i if re has no subexpression with that name.
+func submatch(re *regexp.Regexp, match []string, name string, i int) string {
+	if j := re.SubexpIndex(name); j >= 0 {
+		return match[j]
+	}
+	if i < len(match) {
+		return match[i]
+	}
+	return ""
+}
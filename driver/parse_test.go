@@ -0,0 +1,72 @@
+package driver
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestWithRegex(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"V20230101120000__add_users.up.sql":   "users up",
+		"V20230101120000__add_users.down.sql": "users down",
+		"V20230102090000__add_orders.up.sql":  "orders up",
+		"1_default_style.up.sql":              "ignored",
+	})
+	re := regexp.MustCompile(`^V([0-9]+)__(.*)\.(up|down)\.sql$`)
+	d, err := WithInstance(box, WithRegex(re))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := d.First()
+	if err != nil || v != 20230101120000 {
+		t.Fatalf("expected first version 20230101120000, got %d, %v", v, err)
+	}
+	_, identifier, err := d.ReadDown(v)
+	if err != nil || identifier != "add_users" {
+		t.Errorf("expected add_users, got %q, %v", identifier, err)
+	}
+	if v, err = d.Next(v); err != nil || v != 20230102090000 {
+		t.Errorf("expected next version 20230102090000, got %d, %v", v, err)
+	}
+	if _, err = d.Next(v); err == nil {
+		t.Error("expected default style file to be ignored")
+	}
+}
+
+func TestWithRegexNamedGroups(t *testing.T) {
+	box := newTestBox(map[string]string{"add_users-up-7.sql": ""})
+	re := regexp.MustCompile(`^(?P<identifier>[a-z_]+)-(?P<direction>up|down)-(?P<version>[0-9]+)\.sql$`)
+	d, err := WithInstance(box, WithRegex(re))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, identifier, err := d.ReadUp(7); err != nil || identifier != "add_users" {
+		t.Errorf("expected add_users, got %q, %v", identifier, err)
+	}
+}
+
+func TestWithRegexInvalidDirection(t *testing.T) {
+	box := newTestBox(map[string]string{"1_a.sideways.sql": ""})
+	re := regexp.MustCompile(`^([0-9]+)_(.*)\.([a-z]+)\.sql$`)
+	_, err := WithInstance(box, WithRegex(re), WithStrictParsing())
+	if err == nil || !strings.Contains(err.Error(), "sideways") {
+		t.Errorf("expected invalid direction error, got %v", err)
+	}
+}
+
+func TestWithParser(t *testing.T) {
+	box := newTestBox(map[string]string{"anything": ""})
+	parse := func(raw string) (*source.Migration, error) {
+		return &source.Migration{Version: 3, Identifier: raw, Direction: source.Up, Raw: raw}, nil
+	}
+	d, err := WithInstance(box, WithParser(parse), WithExtensions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, identifier, err := d.ReadUp(3); err != nil || identifier != "anything" {
+		t.Errorf("expected anything, got %q, %v", identifier, err)
+	}
+}
@@ -0,0 +1,49 @@
+package driver
+
+import (
+	"fmt"
+	"os"
+)
+
+// Plan returns the migrations golang-migrate would run to move a
+// database at version current to version target, in the order they
+// would run: the up migrations after current up to and including
+// target when migrating up, and the down migrations from current down
+// to the version after target when migrating down. Version 0 stands for
+// a database without migrations, so Plan(0, v) lists everything up to v
+// and Plan(v, 0) everything down from v. Only the index is used; no body
+// is read unless the source can't report sizes otherwise. Versions in
+// the plan without a file for the direction are only recorded as
+// applied by golang-migrate. Plan fails with an error wrapping
+// os.ErrNotExist if current or target isn't a known version.
+func (d *Driver) Plan(current, target uint) ([]MigrationInfo, error) {
+	infos, err := d.Describe()
+	if err != nil {
+		return nil, err
+	}
+	known := map[uint]bool{}
+	for _, info := range infos {
+		known[info.Version] = true
+	}
+	for _, v := range []uint{current, target} {
+		if v != 0 && !known[v] {
+			return nil, fmt.Errorf("no migration with version %d: %w", v, os.ErrNotExist)
+		}
+	}
+
+	var plan []MigrationInfo
+	if target >= current {
+		for _, info := range infos {
+			if info.Version > current && info.Version <= target {
+				plan = append(plan, info)
+			}
+		}
+		return plan, nil
+	}
+	for i := len(infos) - 1; i >= 0; i-- {
+		if info := infos[i]; info.Version <= current && info.Version > target {
+			plan = append(plan, info)
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,60 @@
+package driver
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	d := newTestDriver(t, map[string]string{
+		"1_init.up.sql":      "",
+		"1_init.down.sql":    "",
+		"3_users.up.sql":     "",
+		"3_users.down.sql":   "",
+		"5_orders.up.sql":    "",
+		"8_cleanup.up.sql":   "",
+		"8_cleanup.down.sql": "",
+	})
+	versions := func(current, target uint) []uint {
+		t.Helper()
+		plan, err := d.Plan(current, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var vs []uint
+		for _, info := range plan {
+			vs = append(vs, info.Version)
+		}
+		return vs
+	}
+	for _, c := range []struct {
+		current, target uint
+		want            []uint
+	}{
+		{0, 8, []uint{1, 3, 5, 8}},
+		{1, 5, []uint{3, 5}},
+		{3, 3, nil},
+		{8, 3, []uint{8, 5}},
+		{5, 0, []uint{5, 3, 1}},
+	} {
+		if got := versions(c.current, c.target); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Plan(%d, %d): expected %v, got %v", c.current, c.target, c.want, got)
+		}
+	}
+
+	plan, err := d.Plan(8, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan[1].Identifier != "orders" || plan[1].HasDown {
+		t.Errorf("expected version 5 without a down migration, got %+v", plan[1])
+	}
+
+	for _, c := range [][2]uint{{2, 5}, {1, 7}} {
+		if _, err := d.Plan(c[0], c[1]); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Plan(%d, %d): expected os.ErrNotExist, got %v", c[0], c[1], err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Preprocessor transforms the body of the migration with version and
+// direction, for example to rewrite schema names or add a preamble. It
+// returns the reader of the transformed body, which may read lazily
+// from r.
+type Preprocessor func(version uint, direction source.Direction, r io.Reader) (io.Reader, error)
+
+// WithPreprocessor adds p to the preprocessors run on the bodies
+// returned by ReadUp and ReadDown, after every other transformation.
+// The option can be given several times; the preprocessors run in the
+// order they were added, each reading the output of the previous one.
+func WithPreprocessor(p Preprocessor) Option {
+	return func(d *Driver) {
+		d.preprocessors = append(d.preprocessors, p)
+	}
+}
+
+// preprocess is the body step running the preprocessors.
+func (d *Driver) preprocess(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error) {
+	if len(d.preprocessors) == 0 {
+		return r, nil
+	}
+	var body io.Reader = r
+	for _, p := range d.preprocessors {
+		next, err := p(m.Version, m.Direction, body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to preprocess migration %s: %v", m.Raw, err)
+		}
+		body = next
+	}
+	return readCloser{Reader: body, Closer: r}, nil
+}
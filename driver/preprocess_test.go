@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestWithPreprocessor(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":   "CREATE TABLE public.a();",
+		"1_init.down.sql": "DROP TABLE public.a;",
+	})
+	tenant := func(version uint, direction source.Direction, r io.Reader) (io.Reader, error) {
+		body, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(strings.Replace(string(body), "public.", "tenant_a.", -1)), nil
+	}
+	preamble := func(version uint, direction source.Direction, r io.Reader) (io.Reader, error) {
+		if direction != source.Up {
+			return r, nil
+		}
+		return io.MultiReader(strings.NewReader("SET lock_timeout = '5s';\n"), r), nil
+	}
+	d, err := WithInstance(box, WithPreprocessor(tenant), WithPreprocessor(preamble))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(1); err != nil || body != "SET lock_timeout = '5s';\nCREATE TABLE tenant_a.a();" {
+		t.Errorf("expected both preprocessors in order, got %q, %v", body, err)
+	}
+	if body, err := readDown(d)(1); err != nil || body != "DROP TABLE tenant_a.a;" {
+		t.Errorf("expected the direction to be passed, got %q, %v", body, err)
+	}
+
+	failing := func(uint, source.Direction, io.Reader) (io.Reader, error) {
+		return nil, errors.New("boom")
+	}
+	d, err = WithInstance(box, WithPreprocessor(failing))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.ReadUp(1); err == nil || err.Error() != "unable to preprocess migration 1_init.up.sql: boom" {
+		t.Errorf("expected the preprocessor error, got %v", err)
+	}
+}
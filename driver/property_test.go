@@ -0,0 +1,118 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// checkIndex checks the invariants of the index d built from a box of
+// files: versions ascend and link both ways, every indexed file can be
+// found, and the report accounts for every file at most once. Bodies
+// may fail to decode, since the files are made up.
+func checkIndex(t *testing.T, d *Driver, files int) {
+	t.Helper()
+	var versions []uint
+	v, err := d.First()
+	for ; err == nil; v, err = d.Next(v) {
+		if n := len(versions); n > 0 {
+			if v <= versions[n-1] {
+				t.Fatalf("version %d follows %d", v, versions[n-1])
+			}
+			if prev, err := d.Prev(v); err != nil || prev != versions[n-1] {
+				t.Fatalf("expected %d before %d, got %d, %v", versions[n-1], v, prev, err)
+			}
+		}
+		versions = append(versions, v)
+	}
+	if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, ErrNoMigrations) {
+		t.Fatalf("unexpected error walking the versions: %v", err)
+	}
+
+	migs := d.list()
+	raws := map[string]bool{}
+	for _, m := range migs {
+		raws[m.Raw] = true
+		read := d.ReadUp
+		if m.Direction == source.Down {
+			read = d.ReadDown
+		}
+		r, _, err := read(m.Version)
+		if errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("indexed file %q can't be found", m.Raw)
+		}
+		if err == nil {
+			r.Close()
+		}
+	}
+	report := d.Report()
+	if report.Migrations != len(migs) || report.Versions != len(versions) {
+		t.Fatalf("report counts %d files in %d versions, index has %d in %d", report.Migrations, report.Versions, len(migs), len(versions))
+	}
+	if len(report.Skipped)+len(raws) > files {
+		t.Fatalf("%d skipped and %d indexed files from a box of %d", len(report.Skipped), len(raws), files)
+	}
+}
+
+func TestIndexProperties(t *testing.T) {
+	property := func(versions []uint16, downs []bool, noise []string) bool {
+		box := memoryBox{}
+		for i, v := range versions {
+			box[fmt.Sprintf("%d_m%d.up.sql", v, i)] = []byte("SELECT 1;")
+			if i < len(downs) && downs[i] {
+				box[fmt.Sprintf("%d_m%d.down.sql", v, i)] = []byte("SELECT 1;")
+			}
+		}
+		for _, name := range noise {
+			box[name] = nil
+		}
+		d, err := WithInstance(box)
+		if err != nil {
+			var dup *ErrDuplicateVersion
+			return errors.As(err, &dup)
+		}
+		checkIndex(t, d, len(box))
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// shuffledBox lists the files of a memoryBox in a random order.
+type shuffledBox struct {
+	memoryBox
+	rand *rand.Rand
+}
+
+func (b shuffledBox) List() []string {
+	names := b.memoryBox.List()
+	b.rand.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+	return names
+}
+
+func TestIndexIgnoresListOrder(t *testing.T) {
+	property := func(versions []uint8, seed int64) bool {
+		box := memoryBox{"R__views.sql": nil, "notes.txt": nil}
+		for i, v := range versions {
+			box[fmt.Sprintf("%d_m%d.up.sql", v, i%2)] = []byte("SELECT 1;")
+		}
+		want, wantErr := WithInstance(box)
+		got, err := WithInstance(shuffledBox{box, rand.New(rand.NewSource(seed))})
+		if wantErr != nil || err != nil {
+			return wantErr != nil && err != nil
+		}
+		wantList, _ := want.List()
+		gotList, _ := got.List()
+		return reflect.DeepEqual(wantList, gotList) && reflect.DeepEqual(want.Report(), got.Report())
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,88 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// WithMinVersion hides migrations with a version below min from the driver.
+func WithMinVersion(min uint) Option {
+	return func(d *Driver) {
+		d.minVersion = min
+	}
+}
+
+// WithMaxVersion hides migrations with a version above max from the driver,
+// so a binary can only migrate up to a vetted version even if later
+// migrations are embedded in it.
+func WithMaxVersion(max uint) Option {
+	return func(d *Driver) {
+		d.maxVersion = max
+		d.limitMax = true
+	}
+}
+
+// inRange reports whether m is within the versions exposed by the driver.
+func (d *Driver) inRange(m *source.Migration) bool {
+	if m.Version < d.minVersion {
+		return false
+	}
+	return !d.limitMax || m.Version <= d.maxVersion
+}
+
+// WithSkipVersions removes the given versions, both up and down
+// migrations, from the driver, for example to neutralize a known-bad
+// migration without rewriting history. Creating the driver fails if
+// a skipped version isn't in the box, or if an up migration requires a
+// skipped version in its header (see WithDependencies), for which the
+// bodies of all up migrations are read. Lint doesn't report the gaps
+// left by skipped versions.
+//
+// golang-migrate moves between adjacent versions of the driver, so
+// a database already at a skipped version can't be migrated down
+// from it; skip versions before they are applied.
+func WithSkipVersions(versions ...uint) Option {
+	return func(d *Driver) {
+		if d.skip == nil {
+			d.skip = map[uint]bool{}
+		}
+		for _, v := range versions {
+			d.skip[v] = true
+		}
+	}
+}
+
+// checkSkipped fails if any version skipped with WithSkipVersions
+// is not among the versions found.
+func (d *Driver) checkSkipped(found map[uint]bool) error {
+	var missing []uint
+	for v := range d.skip {
+		if !found[v] {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	versions := make([]string, len(missing))
+	for i, v := range missing {
+		versions[i] = fmt.Sprint(v)
+	}
+	return fmt.Errorf("unable to skip versions %s: no such migrations", strings.Join(versions, ", "))
+}
+
+// skippedBetween reports whether every version between prev and next,
+// exclusive, is skipped.
+func (d *Driver) skippedBetween(prev, next uint) bool {
+	var n uint
+	for v := range d.skip {
+		if v > prev && v < next {
+			n++
+		}
+	}
+	return n == next-prev-1
+}
@@ -0,0 +1,154 @@
+package driver
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestVersionRange(t *testing.T) {
+	bodies := map[string]string{
+		"1_a.up.sql":   "1",
+		"2_b.up.sql":   "2",
+		"3_c.up.sql":   "3",
+		"3_c.down.sql": "3 down",
+		"4_d.up.sql":   "4",
+	}
+	d := newTestDriver(t, bodies, WithMinVersion(2), WithMaxVersion(3))
+	if v, err := d.First(); err != nil || v != 2 {
+		t.Errorf("expected first version 2, got %d, %v", v, err)
+	}
+	if _, err := d.Next(3); err == nil {
+		t.Error("expected version 4 to be hidden")
+	}
+	if _, _, err := d.ReadUp(4); err == nil {
+		t.Error("expected no up migration for version 4")
+	}
+	if body, err := readDown(d)(3); err != nil || body != "3 down" {
+		t.Errorf("expected down migration of version 3, got %q, %v", body, err)
+	}
+
+	d = newTestDriver(t, bodies, WithMaxVersion(0))
+	if _, err := d.First(); err != ErrNoMigrations {
+		t.Errorf("expected ErrNoMigrations, got %v", err)
+	}
+}
+
+func TestOpenWithVersionRange(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	for _, name := range []string{"1_a.up.sql", "2_b.up.sql", "3_c.up.sql"} {
+		mustWriteFile(t, tmpDir, name, name)
+	}
+
+	p := &Driver{}
+	d, err := p.Open("packr://" + tmpDir + "?min=2&max=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.First(); err != nil || v != 2 {
+		t.Errorf("expected first version 2, got %d, %v", v, err)
+	}
+	if _, err := d.Next(2); err == nil {
+		t.Error("expected a single migration")
+	}
+
+	for _, query := range []string{"?min=two", "?max=-1"} {
+		if _, err := p.Open("packr://" + tmpDir + query); err == nil {
+			t.Errorf("%s: expected error", query)
+		}
+	}
+}
+
+func TestSkipVersions(t *testing.T) {
+	bodies := map[string]string{
+		"1_a.up.sql":     "1",
+		"2_bad.up.sql":   "2",
+		"2_bad.down.sql": "2 down",
+		"3_c.up.sql":     "3",
+	}
+	d := newTestDriver(t, bodies, WithSkipVersions(2))
+	if v, err := d.Next(1); err != nil || v != 3 {
+		t.Errorf("expected version 3 after 1, got %d, %v", v, err)
+	}
+	if _, _, err := d.ReadDown(2); err == nil {
+		t.Error("expected down migration of version 2 to be skipped")
+	}
+	problems, err := d.Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		if p.Kind == ProblemGap {
+			t.Errorf("unexpected gap for skipped version: %s", p)
+		}
+	}
+
+	var migs []source.Migration
+	for name := range bodies {
+		m, _ := source.DefaultParse(name)
+		migs = append(migs, *m)
+	}
+	opener := func(raw string) (io.ReadCloser, error) { return nil, os.ErrNotExist }
+	if _, err := WithMigrations(migs, opener, WithSkipVersions(2, 7)); err == nil || !strings.Contains(err.Error(), "7") {
+		t.Errorf("expected error for unknown skipped version, got %v", err)
+	}
+}
+
+func TestSkipVersionsDependencies(t *testing.T) {
+	bodies := map[string]string{
+		"1_a.up.sql":   "1",
+		"2_bad.up.sql": "2",
+		"3_c.up.sql":   "-- requires: 2\n3",
+		"10_d.up.sql":  "10",
+		"11_e.up.sql":  "11",
+	}
+	_, err := WithInstance(newTestBox(bodies), WithSkipVersions(2))
+	var dep *ErrDependency
+	if !errors.As(err, &dep) || !dep.Skipped || dep.Version != 3 || dep.Requires != 2 {
+		t.Fatalf("expected an *ErrDependency for the skipped version, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "3_c.up.sql requires 2, which is skipped") {
+		t.Errorf("expected the dependency in %q", err)
+	}
+
+	bodies["3_c.up.sql"] = "3"
+	if _, err := WithInstance(newTestBox(bodies), WithSkipVersions(2, 10)); err != nil {
+		t.Errorf("expected versions nothing requires to be skipped, got %v", err)
+	}
+	_, err = WithInstance(newTestBox(bodies), WithSkipVersions(9, 10, 100))
+	if err == nil || !strings.Contains(err.Error(), "unable to skip versions 9, 100:") {
+		t.Errorf("expected the missing versions in numeric order, got %v", err)
+	}
+}
+
+func TestOpenWithSkipVersions(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	for _, name := range []string{"1_a.up.sql", "2_b.up.sql", "3_c.up.sql"} {
+		mustWriteFile(t, tmpDir, name, name)
+	}
+
+	p := &Driver{}
+	d, err := p.Open("packr://" + tmpDir + "?skip=1,%202")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := d.First(); err != nil || v != 3 {
+		t.Errorf("expected first version 3, got %d, %v", v, err)
+	}
+	if _, err := p.Open("packr://" + tmpDir + "?skip=1,x"); err == nil {
+		t.Error("expected error for invalid version")
+	}
+}
@@ -0,0 +1,100 @@
+package driver
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"github.com/gobuffalo/packr"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func init() {
+	source.Register("packr", &Driver{})
+}
+
+// Register registers the driver with golang-migrate under scheme in
+// addition to packr, so URLs like scheme://path/to/box open packr boxes.
+// The options apply to every driver opened through the scheme, before
+// those set by query parameters. Like source.Register, it panics if the
+// scheme is already registered.
+func Register(scheme string, opts ...Option) {
+	source.Register(scheme, &Driver{scheme: scheme, defaults: opts})
+}
+
+// RegisterFS registers a driver with golang-migrate under scheme that
+// serves migrations from fsys, such as an embed.FS. The path of a URL
+// like scheme://migrations is the directory inside fsys, which may be
+// empty. The options apply to every driver opened through the scheme,
+// before those set by query parameters. Like source.Register, it panics
+// if the scheme is already registered.
+func RegisterFS(scheme string, fsys fs.FS, opts ...Option) {
+	if fsys == nil {
+		panic("driver: RegisterFS fsys is nil")
+	}
+	source.Register(scheme, &Driver{
+		scheme:   scheme,
+		defaults: opts,
+		resolve: func(dir string) (Box, error) {
+			if dir == "" {
+				return fsBox{fsys}, nil
+			}
+			sub, err := fs.Sub(fsys, dir)
+			if err != nil {
+				return nil, err
+			}
+			return fsBox{sub}, nil
+		},
+	})
+}
+
+// RegisterHTTPFS registers a driver with golang-migrate under scheme
+// that serves migrations from the http.FileSystem returned by dir for
+// the path of a URL. This supports embedding libraries that expose
+// directories as http.FileSystem, like github.com/markbates/pkger,
+// without the driver depending on them:
+//
+//	pkger.Include("/migrations")
+//	driver.RegisterHTTPFS("pkger", func(p string) http.FileSystem {
+//		return pkger.Dir("/" + p)
+//	})
+//
+// makes pkger://migrations serve the migrations bundled by pkger.
+// The options apply to every driver opened through the scheme, before
+// those set by query parameters. Like source.Register, it panics if the
+// scheme is already registered.
+func RegisterHTTPFS(scheme string, dir func(path string) http.FileSystem, opts ...Option) {
+	if dir == nil {
+		panic("driver: RegisterHTTPFS dir is nil")
+	}
+	source.Register(scheme, &Driver{
+		scheme:   scheme,
+		defaults: opts,
+		resolve: func(path string) (Box, error) {
+			hfs := dir(path)
+			if hfs == nil {
+				return nil, fmt.Errorf("no file system for %s", path)
+			}
+			return httpFSBox{hfs}, nil
+		},
+	})
+}
+
+// urlScheme returns the scheme of URLs given to Open.
+func (d *Driver) urlScheme() string {
+	if d.scheme == "" {
+		return "packr"
+	}
+	return d.scheme
+}
+
+// resolveBox returns the box at boxPath for Open.
+func (d *Driver) resolveBox(boxPath string) (Box, error) {
+	if d.resolve != nil {
+		return d.resolve(boxPath)
+	}
+	if boxPath == "" {
+		return nil, fmt.Errorf("no box path")
+	}
+	return v1Box{packr.NewBox(boxPath)}, nil
+}
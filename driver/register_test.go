@@ -0,0 +1,115 @@
+package driver
+
+import (
+	"io/fs"
+	"net/http"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// registered keeps repeated test runs from registering the schemes twice.
+var registered sync.Once
+
+func registerTestSchemes() {
+	registered.Do(func() {
+		Register("packr-strict", WithStrictParsing())
+		RegisterFS("embed-test", fstest.MapFS{
+			"1_root.up.sql":            {Data: []byte("root")},
+			"migrations/1_init.up.sql": {Data: []byte("init")},
+			"migrations/pg/2_x.up.sql": {Data: []byte("x")},
+		})
+		RegisterHTTPFS("pkger-test", func(p string) http.FileSystem {
+			if _, err := fs.Stat(bundled, p); err != nil {
+				return nil
+			}
+			sub, err := fs.Sub(bundled, p)
+			if err != nil {
+				return nil
+			}
+			return http.FS(sub)
+		})
+	})
+}
+
+// bundled stands in for the files bundled by pkger.
+var bundled = fstest.MapFS{
+	"migrations/1_init.up.sql":       {Data: []byte("init")},
+	"migrations/1_init.down.sql":     {Data: []byte("drop")},
+	"migrations/postgres/2_x.up.sql": {Data: []byte("x")},
+}
+
+func TestRegister(t *testing.T) {
+	registerTestSchemes()
+
+	tmpDir := t.TempDir()
+	mustWriteFile(t, tmpDir, "1_init.up.sql", "init")
+	d, err := source.Open("packr-strict://" + tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBody(t, d, 1, "init")
+
+	mustWriteFile(t, tmpDir, "notes.sql", "")
+	if _, err := source.Open("packr-strict://" + tmpDir); err == nil {
+		t.Error("expected the registered options to apply")
+	}
+	if _, err := source.Open("packr-strict://" + tmpDir + "?ext=.md"); err != nil {
+		t.Errorf("expected query parameters to apply after the registered options, got %v", err)
+	}
+}
+
+func TestRegisterFS(t *testing.T) {
+	registerTestSchemes()
+
+	d, err := source.Open("embed-test://migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBody(t, d, 1, "init")
+
+	d, err = source.Open("embed-test://migrations?dir=pg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBody(t, d, 2, "x")
+
+	d, err = source.Open("embed-test://")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBody(t, d, 1, "root")
+}
+
+func expectBody(t *testing.T, d source.Driver, version uint, body string) {
+	t.Helper()
+	got, err := readUp(d.(*Driver))(version)
+	if err != nil || got != body {
+		t.Errorf("expected %q for version %d, got %q, %v", body, version, got, err)
+	}
+}
+
+func TestRegisterHTTPFS(t *testing.T) {
+	registerTestSchemes()
+
+	d, err := source.Open("pkger-test://migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBody(t, d, 1, "init")
+	if _, err := d.Next(1); err == nil {
+		t.Error("expected files in subdirectories to be left out")
+	}
+
+	d, err = source.Open("pkger-test://migrations?dir=postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBody(t, d, 2, "x")
+
+	if _, err := source.Open("pkger-test://missing"); err == nil {
+		t.Error("expected an error for a missing file system")
+	}
+}
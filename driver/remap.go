@@ -0,0 +1,78 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// WithVersionMap serves migrations under historical versions, for
+// example after old migrations were renumbered when repositories were
+// merged and schema_migrations still records the old numbers. remap
+// maps each historical version to the version in the file name, so
+// map[uint]uint{3: 1003} serves 1003_users.up.sql as version 3; other
+// versions are served as they are. Skipped versions, ranges and the
+// baseline refer to the historical versions. Creating the driver fails
+// if a mapped file version isn't in the box, two historical versions
+// map to the same file, or a mapped version collides with another
+// migration.
+func WithVersionMap(remap map[uint]uint) Option {
+	return func(d *Driver) {
+		if d.versionMap == nil {
+			d.versionMap = make(map[uint]uint, len(remap))
+		}
+		for old, v := range remap {
+			d.versionMap[old] = v
+		}
+	}
+}
+
+// historical returns the versions of files mapped to historical
+// versions by WithVersionMap, keyed by the version of the file.
+func (d *Driver) historical() (map[uint]uint, error) {
+	if len(d.versionMap) == 0 {
+		return nil, nil
+	}
+	inverse := make(map[uint]uint, len(d.versionMap))
+	var olds []uint
+	for old := range d.versionMap {
+		olds = append(olds, old)
+	}
+	sort.Slice(olds, func(i, j int) bool { return olds[i] < olds[j] })
+	for _, old := range olds {
+		v := d.versionMap[old]
+		if other, ok := inverse[v]; ok {
+			return nil, fmt.Errorf("versions %d and %d both map to version %d", other, old, v)
+		}
+		inverse[v] = old
+	}
+	return inverse, nil
+}
+
+// remapped returns m with its historical version, if it has one.
+func remapped(inverse map[uint]uint, m *source.Migration) *source.Migration {
+	old, ok := inverse[m.Version]
+	if !ok {
+		return m
+	}
+	c := *m
+	c.Version = old
+	return &c
+}
+
+// checkRemapped checks that every mapped version was found.
+func (d *Driver) checkRemapped(inverse map[uint]uint, found map[uint]bool) error {
+	var missing []string
+	for v := range inverse {
+		if !found[v] {
+			missing = append(missing, fmt.Sprint(v))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("unable to map versions %s: no such migrations", strings.Join(missing, ", "))
+}
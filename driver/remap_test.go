@@ -0,0 +1,83 @@
+package driver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithVersionMap(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":       "CREATE TABLE a();",
+		"1003_users.up.sql":   "CREATE TABLE users();",
+		"1003_users.down.sql": "DROP TABLE users;",
+		"1004_orders.up.sql":  "CREATE TABLE orders();",
+		"7_later.up.sql":      "CREATE TABLE later();",
+	}
+	d, err := WithInstance(newTestBox(files), WithVersionMap(map[uint]uint{3: 1003, 4: 1004}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []uint
+	for v, err := d.First(); err == nil; v, err = d.Next(v) {
+		versions = append(versions, v)
+	}
+	if want := []uint{1, 3, 4, 7}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("expected versions %v, got %v", want, versions)
+	}
+	if prev, err := d.Prev(7); err != nil || prev != 4 {
+		t.Errorf("expected 4 before 7, got %d, %v", prev, err)
+	}
+	r, identifier, err := d.ReadDown(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if identifier != "users" {
+		t.Errorf("expected identifier users, got %s", identifier)
+	}
+	if body, err := readUp(d)(4); err != nil || body != "CREATE TABLE orders();" {
+		t.Errorf("expected the body of 1004_orders.up.sql, got %q, %v", body, err)
+	}
+	if _, _, err := d.ReadUp(1003); err == nil {
+		t.Error("expected the file version not to be served")
+	}
+}
+
+func TestWithVersionMapErrors(t *testing.T) {
+	files := map[string]string{
+		"1_init.up.sql":     "",
+		"1003_users.up.sql": "",
+	}
+	for _, c := range []struct {
+		remap map[uint]uint
+		want  string
+	}{
+		{map[uint]uint{3: 1005}, "unable to map versions 1005"},
+		{map[uint]uint{3: 1003, 4: 1003}, "versions 3 and 4 both map to version 1003"},
+		{map[uint]uint{1: 1003}, "conflicting migrations"},
+	} {
+		_, err := WithInstance(newTestBox(files), WithVersionMap(c.remap))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("expected error containing %q for %v, got %v", c.want, c.remap, err)
+		}
+	}
+}
+
+func TestVersionMapQuery(t *testing.T) {
+	opts, err := queryOptions(map[string][]string{"remap": {"3:1003, 4:1004"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Driver{}
+	d.apply(opts)
+	if want := map[uint]uint{3: 1003, 4: 1004}; !reflect.DeepEqual(d.versionMap, want) {
+		t.Errorf("expected %v, got %v", want, d.versionMap)
+	}
+	for _, v := range []string{"3", "x:1003", "3:y"} {
+		if _, err := queryOptions(map[string][]string{"remap": {v}}); err == nil {
+			t.Errorf("expected error for remap %s", v)
+		}
+	}
+}
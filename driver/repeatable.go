@@ -0,0 +1,97 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// repeatableExt marks repeatable migrations named like views.repeat.sql.
+const repeatableExt = ".repeat"
+
+// Repeatable describes a repeatable migration: a file that isn't
+// versioned but is applied again whenever it changes, typically to
+// recreate views or functions. Repeatable migrations are named like
+// R__views.sql, as in Flyway, or views.repeat.sql. They are left out of
+// the versioned migrations served to golang-migrate.
+type Repeatable struct {
+	// Name is the file name without the R__ prefix and its extensions.
+	Name string `json:"name"`
+	File string `json:"file"`
+	// Checksum is the hex encoded SHA-256 checksum of the stored file.
+	// Applications keep the checksum of the last applied version and
+	// apply the migration again when it differs.
+	Checksum string `json:"checksum"`
+}
+
+// Repeatables returns the repeatable migrations known to the driver,
+// sorted by name.
+func (d *Driver) Repeatables() ([]Repeatable, error) {
+	if err := d.alive(); err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	open, migs := d.open, d.repeatables
+	d.mu.RUnlock()
+
+	repeatables := make([]Repeatable, 0, len(migs))
+	for _, m := range migs {
+		sum, err := checksum(open, m.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read migration %s: %v", m.Raw, err)
+		}
+		repeatables = append(repeatables, Repeatable{Name: m.Identifier, File: m.Raw, Checksum: sum})
+	}
+	return repeatables, nil
+}
+
+// ReadRepeatable returns the body of the repeatable migration name,
+// transformed like the bodies returned by ReadUp. If there is no such
+// migration, the error matches os.ErrNotExist.
+func (d *Driver) ReadRepeatable(name string) (io.ReadCloser, error) {
+	if err := d.alive(); err != nil {
+		return nil, err
+	}
+	d.mu.RLock()
+	open, migs := d.open, d.repeatables
+	d.mu.RUnlock()
+	for _, m := range migs {
+		if m.Identifier == name {
+			return d.stored(open, m)
+		}
+	}
+	return nil, &os.PathError{Op: "read repeatable", Path: name, Err: os.ErrNotExist}
+}
+
+// isRepeatable reports whether file, relative to the migration
+// directory, is a repeatable migration.
+func (d *Driver) isRepeatable(file string) bool {
+	if strings.Contains(file, "/") {
+		return false
+	}
+	return strings.HasPrefix(file, "R__") || strings.HasSuffix(d.stem(file), repeatableExt)
+}
+
+// repeatable returns the migration of the repeatable migration file.
+func (d *Driver) repeatable(file string) *source.Migration {
+	name := strings.TrimSuffix(d.stem(file), repeatableExt)
+	return &source.Migration{
+		Identifier: strings.TrimPrefix(name, "R__"),
+		Direction:  source.Up,
+		Raw:        file,
+	}
+}
+
+// stem returns file without its compression, template and content
+// extensions.
+func (d *Driver) stem(file string) string {
+	name := d.withoutCompressionExt(file)
+	if d.isTemplate(name) {
+		name = strings.TrimSuffix(name, templateExt)
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
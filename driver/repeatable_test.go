@@ -0,0 +1,73 @@
+package driver
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRepeatables(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":        "CREATE TABLE a();",
+		"R__refresh_views.sql": "CREATE VIEW v;",
+		"functions.repeat.sql": "CREATE FUNCTION f;",
+		"functions.repeat.cql": "not allowed",
+		"2_users.up.sql":       "CREATE TABLE users();",
+	})
+	d, err := WithInstance(box, WithStrictParsing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repeatables, err := d.Repeatables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Repeatable{
+		{Name: "functions", File: "functions.repeat.sql", Checksum: sha("CREATE FUNCTION f;")},
+		{Name: "refresh_views", File: "R__refresh_views.sql", Checksum: sha("CREATE VIEW v;")},
+	}
+	if !reflect.DeepEqual(repeatables, want) {
+		t.Errorf("expected %+v, got %+v", want, repeatables)
+	}
+
+	r, err := d.ReadRepeatable("refresh_views")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil || string(body) != "CREATE VIEW v;" {
+		t.Errorf("expected the repeatable body, got %q, %v", body, err)
+	}
+	if _, err := d.ReadRepeatable("missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got %v", err)
+	}
+
+	next, err := d.Next(1)
+	if err != nil || next != 2 {
+		t.Errorf("expected the versioned migrations to be unaffected, got %d, %v", next, err)
+	}
+}
+
+func TestRepeatablesAcrossBoxes(t *testing.T) {
+	d, err := WithInstances(
+		newTestBox(map[string]string{"R__views.sql": "first"}),
+		newTestBox(map[string]string{"R__views.sql": "second", "R__functions.sql": "functions"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"views": "first", "functions": "functions"} {
+		r, err := d.ReadRepeatable(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil || string(body) != want {
+			t.Errorf("%s: expected %q, got %q, %v", name, want, body, err)
+		}
+	}
+}
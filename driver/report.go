@@ -0,0 +1,45 @@
+package driver
+
+// Report describes the index the driver serves: what was indexed and
+// which files were left out, so a migration that didn't run can be
+// explained without strict mode.
+type Report struct {
+	// Migrations is the number of indexed migration files and Versions
+	// the number of versions they make up.
+	Migrations int `json:"migrations"`
+	Versions   int `json:"versions"`
+	// First and Last are the lowest and highest versions, both 0 if
+	// there are none.
+	First uint `json:"first"`
+	Last  uint `json:"last"`
+	// Skipped are the files that weren't indexed, in the order they
+	// were found.
+	Skipped []SkippedFile `json:"skipped,omitempty"`
+}
+
+// SkippedFile is a file left out of the index and the reason, such as
+// a parse error, a disallowed extension or a skipped version.
+type SkippedFile struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// Report returns the report of the index built last, when the driver
+// was created or reloaded.
+func (d *Driver) Report() Report {
+	d.mu.RLock()
+	r := d.report
+	d.mu.RUnlock()
+	r.Skipped = append([]SkippedFile(nil), r.Skipped...)
+	return r
+}
+
+// newReport completes the report of the files skipped while idx was
+// built with what it indexed.
+func newReport(idx *index, skipped []SkippedFile) Report {
+	r := Report{Migrations: idx.migrations.count(), Versions: len(idx.migrations.versions), Skipped: skipped}
+	if n := len(idx.migrations.versions); n > 0 {
+		r.First, r.Last = idx.migrations.versions[0], idx.migrations.versions[n-1]
+	}
+	return r
+}
@@ -0,0 +1,63 @@
+package driver
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestReport(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":     "",
+		"1_init.down.sql":   "",
+		"2_bad.up.sql":      "",
+		"3_users.up.sql":    "",
+		"9_future.up.sql":   "",
+		"init.sql":          "",
+		"notes.txt":         "",
+		"R__views.sql":      "",
+		"3_users.down.sql":  "",
+		"4_orders.down.sql": "",
+	})
+	d, err := WithInstance(box, WithSkipVersions(2), WithMaxVersion(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := d.Report()
+	// files are skipped in the order the box lists them
+	sort.Slice(r.Skipped, func(i, j int) bool { return r.Skipped[i].File < r.Skipped[j].File })
+	want := Report{
+		Migrations: 5,
+		Versions:   3,
+		First:      1,
+		Last:       4,
+		Skipped: []SkippedFile{
+			{File: "2_bad.up.sql", Reason: "version skipped"},
+			{File: "9_future.up.sql", Reason: "version out of range"},
+			{File: "init.sql", Reason: source.ErrParse.Error()},
+			{File: "notes.txt", Reason: "extension not allowed"},
+		},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("expected %+v, got %+v", want, r)
+	}
+
+	r.Skipped[0].File = "changed"
+	if d.Report().Skipped[0].File == "changed" {
+		t.Error("expected Report to return a copy")
+	}
+}
+
+func TestReportEmpty(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{"README.md": ""}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Report{Skipped: []SkippedFile{{File: "README.md", Reason: "extension not allowed"}}}
+	if r := d.Report(); !reflect.DeepEqual(r, want) {
+		t.Errorf("expected %+v, got %+v", want, r)
+	}
+}
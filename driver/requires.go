@@ -0,0 +1,105 @@
+package driver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// WithDependencies makes the driver check the dependencies that up
+// migrations declare in their header, such as
+//
+//	-- requires: 20240110120000, 20240112093000
+//
+// when it is created. Every version required must have an up migration
+// that comes before the migration requiring it, otherwise creating the
+// driver fails with an error unwrapping to an *ErrDependency. This
+// catches migrations merged with versions out of order before they are
+// applied. The bodies of all up migrations are read for the check.
+func WithDependencies() Option {
+	return func(d *Driver) {
+		d.dependencies = true
+	}
+}
+
+// ErrDependency reports a migration requiring a version that doesn't
+// come before it.
+type ErrDependency struct {
+	Version  uint
+	File     string
+	Requires uint
+	// Missing is set if there is no up migration with the required
+	// version at all, rather than a later one.
+	Missing bool
+	// Skipped is set if the required version is skipped with
+	// WithSkipVersions.
+	Skipped bool
+}
+
+func (e *ErrDependency) Error() string {
+	if e.Skipped {
+		return fmt.Sprintf("%s requires %d, which is skipped", e.File, e.Requires)
+	}
+	if e.Missing {
+		return fmt.Sprintf("%s requires %d, which doesn't exist", e.File, e.Requires)
+	}
+	return fmt.Sprintf("%s requires %d, which comes after it", e.File, e.Requires)
+}
+
+// verifyDependencies checks the dependencies declared by the up
+// migrations of idx if WithDependencies is used, and that none of them
+// requires a skipped version if WithSkipVersions is.
+func (d *Driver) verifyDependencies(idx *index) error {
+	if !d.dependencies && len(d.skip) == 0 {
+		return nil
+	}
+	ups := map[uint]bool{}
+	var migs []*source.Migration
+	for _, m := range idx.list() {
+		if m.Direction == source.Up {
+			ups[m.Version] = true
+			migs = append(migs, m)
+		}
+	}
+
+	var errs []error
+	var msgs []string
+	for _, m := range migs {
+		meta, err := d.metadataIn(idx, m)
+		if err != nil {
+			return fmt.Errorf("unable to read migration %s: %v", m.Raw, err)
+		}
+		required, err := parseRequires(meta["requires"])
+		if err != nil {
+			return fmt.Errorf("invalid requires in %s: %v", m.Raw, err)
+		}
+		for _, v := range required {
+			if !d.skip[v] && (!d.dependencies || ups[v] && v < m.Version) {
+				continue
+			}
+			err := &ErrDependency{Version: m.Version, File: m.Raw, Requires: v, Missing: !ups[v], Skipped: d.skip[v]}
+			errs = append(errs, err)
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &wrapped{"unresolved migration dependencies: " + strings.Join(msgs, ", "), errs[0]}
+}
+
+// parseRequires returns the versions in the value of a requires header,
+// separated by commas or spaces.
+func parseRequires(value string) ([]uint, error) {
+	var versions []uint
+	for _, field := range strings.FieldsFunc(value, func(c rune) bool { return c == ',' || c == ' ' }) {
+		v, err := strconv.ParseUint(field, 10, 0)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, uint(v))
+	}
+	return versions, nil
+}
@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithDependencies(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":     "CREATE TABLE a();",
+		"2_users.up.sql":    "-- requires: 1\nCREATE TABLE users();",
+		"3_orders.up.sql":   "-- requires: 1, 2\nCREATE TABLE orders();",
+		"3_orders.down.sql": "-- requires: 9\nDROP TABLE orders;",
+	})
+	if _, err := WithInstance(box, WithDependencies()); err != nil {
+		t.Errorf("expected satisfied dependencies, got %v", err)
+	}
+
+	box = newTestBox(map[string]string{
+		"1_init.up.sql":   "-- requires: 2\nCREATE TABLE a();",
+		"2_users.up.sql":  "CREATE TABLE users();",
+		"3_orders.up.sql": "-- requires: 7\nCREATE TABLE orders();",
+	})
+	if _, err := WithInstance(box); err != nil {
+		t.Errorf("expected dependencies to be ignored by default, got %v", err)
+	}
+	_, err := WithInstance(box, WithDependencies())
+	var dep *ErrDependency
+	if !errors.As(err, &dep) {
+		t.Fatalf("expected *ErrDependency, got %v", err)
+	}
+	if want := (&ErrDependency{Version: 1, File: "1_init.up.sql", Requires: 2}); !reflect.DeepEqual(dep, want) {
+		t.Errorf("expected %+v, got %+v", want, dep)
+	}
+	if msg := "unresolved migration dependencies: 1_init.up.sql requires 2, which comes after it, 3_orders.up.sql requires 7, which doesn't exist"; err.Error() != msg {
+		t.Errorf("expected %q, got %q", msg, err)
+	}
+}
+
+func TestParseRequires(t *testing.T) {
+	got, err := parseRequires("20240110120000, 20240112093000 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint{20240110120000, 20240112093000, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if _, err := parseRequires("1, two"); err == nil {
+		t.Error("expected an error for a version that isn't a number")
+	}
+}
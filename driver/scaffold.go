@@ -0,0 +1,112 @@
+package driver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// NextVersion returns the version for a new migration created at now,
+// following the numbering policy of the driver: the latest version plus
+// one for sequence numbers, and the time for timestamps. Without a
+// policy, timestamps are continued if the latest version is one, and
+// sequence numbers otherwise. It fails if the timestamp wouldn't sort
+// after the latest version.
+func (d *Driver) NextVersion(now time.Time) (uint, error) {
+	if err := d.alive(); err != nil {
+		return 0, err
+	}
+	var latest uint
+	migs := d.list()
+	if len(migs) > 0 {
+		latest = migs[len(migs)-1].Version
+	}
+	policy := d.numbering
+	if policy == AnyNumbering {
+		policy = SequenceNumbering
+		for _, p := range []NumberingPolicy{UnixNumbering, DateTimeNumbering} {
+			if len(migs) > 0 && p.allows(latest) {
+				policy = p
+			}
+		}
+	}
+
+	var next uint
+	switch policy {
+	case UnixNumbering:
+		next = uint(now.Unix())
+	case DateTimeNumbering:
+		v, err := strconv.ParseUint(now.UTC().Format("20060102150405"), 10, 0)
+		if err != nil {
+			return 0, err
+		}
+		next = uint(v)
+	default:
+		next = latest + 1
+	}
+	if len(migs) > 0 && next <= latest {
+		return 0, fmt.Errorf("the next %s version %d doesn't sort after the latest version %d", policy, next, latest)
+	}
+	return next, nil
+}
+
+// Scaffold creates the files of a new, empty migration called name in
+// dir, the directory on disk the box is built from, and returns their
+// paths. The files are named like 42_add_users.up.sql and
+// 42_add_users.down.sql, with the version chosen by NextVersion, the
+// name normalized by NormalizeIdentifier, and the first of the
+// extensions configured with WithExtensions in sort order, .sql by
+// default. Existing files are never overwritten; if a file can't be
+// created, those already created are removed again.
+func (d *Driver) Scaffold(dir, name string) ([]string, error) {
+	identifier := NormalizeIdentifier(name)
+	if identifier == "" {
+		return nil, fmt.Errorf("invalid migration name '%s'", name)
+	}
+	version, err := d.NextVersion(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ext := d.scaffoldExt()
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(dir, fmt.Sprintf("%d_%s.%s%s", version, identifier, direction, ext))
+		if err := createEmpty(file); err != nil {
+			for _, created := range files {
+				os.Remove(created)
+			}
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// createEmpty creates the empty file name, failing if it exists.
+func createEmpty(name string) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return err
+	}
+	return nil
+}
+
+// scaffoldExt returns the extension of scaffolded migrations.
+func (d *Driver) scaffoldExt() string {
+	ext := ""
+	for e := range d.extensions {
+		if ext == "" || e < ext {
+			ext = e
+		}
+	}
+	if ext == "" {
+		return ".sql"
+	}
+	return ext
+}
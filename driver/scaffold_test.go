@@ -0,0 +1,110 @@
+package driver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNextVersion(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		name  string
+		files map[string]string
+		opts  []Option
+		want  uint
+	}{
+		{"empty", map[string]string{}, nil, 1},
+		{"sequence", map[string]string{"1_a.up.sql": "", "7_b.down.sql": ""}, nil, 8},
+		{"unix", map[string]string{"1700000000_a.up.sql": ""}, nil, 1704888000},
+		{"datetime", map[string]string{"20230101000000_a.up.sql": ""}, nil, 20240110120000},
+		{"policy", map[string]string{}, []Option{WithNumberingPolicy(DateTimeNumbering)}, 20240110120000},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			d, err := WithInstance(newTestBox(c.files), c.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := d.NextVersion(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != c.want {
+				t.Errorf("expected %d, got %d", c.want, v)
+			}
+		})
+	}
+
+	d, err := WithInstance(newTestBox(map[string]string{"20250101000000_a.up.sql": ""}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.NextVersion(now); err == nil {
+		t.Error("expected error for timestamp before the latest version")
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, dir, "1_init.up.sql", "CREATE TABLE a();")
+	d, err := WithInstance(newTestBox(map[string]string{"1_init.up.sql": "CREATE TABLE a();"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := d.Scaffold(dir, "Add users!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "2_add_users.up.sql"), filepath.Join(dir, "2_add_users.down.sql")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+	for _, file := range want {
+		if _, err := os.Stat(file); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := d.Scaffold(dir, "add users"); !os.IsExist(err) {
+		t.Errorf("expected existing files to be kept, got %v", err)
+	}
+	if _, err := d.Scaffold(dir, "!!"); err == nil {
+		t.Error("expected error for invalid name")
+	}
+}
+
+func TestScaffoldCleansUp(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, dir, "2_add_users.down.sql", "left over")
+	d, err := WithInstance(newTestBox(map[string]string{"1_init.up.sql": "CREATE TABLE a();"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Scaffold(dir, "add users"); !os.IsExist(err) {
+		t.Fatalf("expected the existing down file to fail the scaffold, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2_add_users.up.sql")); !os.IsNotExist(err) {
+		t.Errorf("expected the created up file to be removed, got %v", err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "2_add_users.down.sql")); err != nil || string(data) != "left over" {
+		t.Errorf("expected the existing down file to be kept, got %q, %v", data, err)
+	}
+}
+
+func TestScaffoldExtension(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{}), WithExtensions("js", ".cql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.Scaffold(t.TempDir(), "init")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(files[0]) != "1_init.up.cql" {
+		t.Errorf("expected the first extension in sort order, got %s", files[0])
+	}
+}
@@ -0,0 +1,27 @@
+package driver
+
+// seedsDir is the directory next to the schema migrations holding seeds.
+const seedsDir = "seeds"
+
+// WithSeeds makes the driver serve the seed migrations kept in the seeds
+// directory below the migration directory, such as db/seeds for a root
+// of db, instead of the schema migrations. Seeds are numbered on their
+// own and ignored by drivers serving the schema migrations, so whether
+// to apply them is decided at runtime by running a second migrate
+// instance with the seeds driver, which should record its version
+// separately, for example in its own migrations table.
+func WithSeeds() Option {
+	return func(d *Driver) {
+		d.seeds = true
+	}
+}
+
+// isSeed reports whether file, relative to the migration directory,
+// is a seed that a schema driver ignores.
+func (d *Driver) isSeed(file string) bool {
+	if d.seeds {
+		return false
+	}
+	_, ok := inDir(file, seedsDir)
+	return ok
+}
@@ -0,0 +1,64 @@
+package driver
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestWithSeeds(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"db/1_init.up.sql":        "schema 1",
+		"db/2_users.up.sql":       "schema 2",
+		"db/seeds/1_users.up.sql": "seed 1",
+	})
+	schema, err := WithInstance(box, WithRoot("db"), WithStrictParsing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := schema.Next(1); err != nil || v != 2 {
+		t.Errorf("expected schema version 2, got %d, %v", v, err)
+	}
+	if body, err := readUp(schema)(1); err != nil || body != "schema 1" {
+		t.Errorf("expected schema migration, got %q, %v", body, err)
+	}
+
+	seeds, err := WithInstance(box, WithRoot("db"), WithSeeds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(seeds)(1); err != nil || body != "seed 1" {
+		t.Errorf("expected seed, got %q, %v", body, err)
+	}
+	if _, err := seeds.Next(1); err == nil {
+		t.Error("expected a single seed")
+	}
+}
+
+func TestOpenWithSeeds(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := os.MkdirAll(path.Join(tmpDir, "seeds"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, tmpDir, "1_init.up.sql", "schema")
+	mustWriteFile(t, path.Join(tmpDir, "seeds"), "5_fixtures.up.sql", "seed")
+
+	p := &Driver{}
+	for query, want := range map[string]uint{"": 1, "?seeds=false": 1, "?seeds=true": 5} {
+		d, err := p.Open("packr://" + tmpDir + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, err := d.First(); err != nil || v != want {
+			t.Errorf("%q: expected first version %d, got %d, %v", query, want, v, err)
+		}
+	}
+	if _, err := p.Open("packr://" + tmpDir + "?seeds=maybe"); err == nil {
+		t.Error("expected error for invalid seeds value")
+	}
+}
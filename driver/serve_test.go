@@ -0,0 +1,90 @@
+package driver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestHandler(t *testing.T) {
+	d, err := WithInstance(newTestBox(map[string]string{
+		"1_init.sql":  "-- +goose Up\nCREATE TABLE a();\n-- +goose Down\nDROP TABLE a;\n",
+		"2_users.sql": "-- +goose Up\nCREATE TABLE users();\n-- +goose Down\nDROP TABLE users;\n",
+	}), WithGooseFormat())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	get := func(path string, v interface{}) int {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	var nodes []treeNode
+	if status := get("/api/v4/projects/1/repository/tree?path=db&ref=main", &nodes); status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", status)
+	}
+	var names []string
+	for _, node := range nodes {
+		if node.Type != "blob" || node.Path != "db/"+node.Name {
+			t.Errorf("unexpected node %+v", node)
+		}
+		if len(source.Regex.FindStringSubmatch(node.Name)) != 5 {
+			t.Errorf("expected %s to parse as a migration", node.Name)
+		}
+		names = append(names, node.Name)
+	}
+	want := []string{"1_init.up.sql", "1_init.down.sql", "2_users.up.sql", "2_users.down.sql"}
+	if len(names) != len(want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, names)
+		}
+	}
+
+	for path, body := range map[string]string{
+		"/api/v4/projects/1/repository/files/db%2F1_init.down.sql?ref=main": "DROP TABLE a;\n",
+		"/api/v4/projects/1/repository/files/%2F2_users.down.sql":           "DROP TABLE users;\n",
+	} {
+		var file repositoryFile
+		if status := get(path, &file); status != http.StatusOK {
+			t.Fatalf("expected status 200 for %s, got %d", path, status)
+		}
+		content, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != body {
+			t.Errorf("expected %q for %s, got %q", body, path, content)
+		}
+	}
+
+	var message map[string]string
+	if status := get("/api/v4/projects/1/repository/files/db%2F3_x.up.sql", &message); status != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", status)
+	}
+	if status := get("/other", &message); status != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", status)
+	}
+
+	d.Close()
+	if status := get("/api/v4/projects/1/repository/tree", &message); status != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 after close, got %d", status)
+	}
+}
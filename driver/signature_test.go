@@ -0,0 +1,122 @@
+package driver
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestWithSignatureKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"1_init.up.sql":   "create",
+		"1_init.down.sql": "drop",
+	}
+	signed := map[string]string{}
+	for name, body := range files {
+		signed[name] = body
+	}
+	signed["1_init.up.sql.sig"] = string(ed25519.Sign(priv, []byte("create")))
+	signed["1_init.down.sql.sig"] = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("drop"))) + "\n"
+
+	d, err := WithInstance(newTestBox(signed), WithSignatureKey(pub), WithExtensions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(1); err != nil || body != "create" {
+		t.Errorf("expected verified body, got %q, %v", body, err)
+	}
+	if body, err := readDown(d)(1); err != nil || body != "drop" {
+		t.Errorf("expected verified body, got %q, %v", body, err)
+	}
+
+	tampered := map[string]string{}
+	for name, body := range signed {
+		tampered[name] = body
+	}
+	tampered["1_init.up.sql"] = "create; drop everything"
+	if _, err := WithInstance(newTestBox(tampered), WithSignatureKey(pub)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for tampered file, got %v", err)
+	}
+	if _, err := WithInstance(newTestBox(files), WithSignatureKey(pub)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for missing signature, got %v", err)
+	}
+}
+
+func TestSignatureVerifiedOnRead(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := "create"
+	box := memoryBox{
+		"1_init.up.sql":     []byte(body),
+		"1_init.up.sql.sig": ed25519.Sign(priv, []byte(body)),
+	}
+	d, err := WithInstance(box, WithSignatureKey(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	box["1_init.up.sql"] = []byte("altered after creation")
+	if _, _, err := d.ReadUp(1); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected read to fail with ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestSignaturesOfAddedBoxes(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(name, body string) memoryBox {
+		return memoryBox{name: []byte(body), name + ".sig": ed25519.Sign(priv, []byte(body))}
+	}
+	d, err := WithInstance(sign("1_a.up.sql", "a"), WithSignatureKey(pub), WithBoxes(sign("2_b.up.sql", "b")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(2); err != nil || body != "b" {
+		t.Errorf("expected verified body from added box, got %q, %v", body, err)
+	}
+}
+
+type closeCounter struct {
+	io.Reader
+	closes int
+}
+
+func (c *closeCounter) Close() error {
+	c.closes++
+	return nil
+}
+
+func TestFailedVerificationClosesOnce(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Driver{signatureKey: pub}
+	file := &closeCounter{Reader: strings.NewReader("create")}
+	open := func(raw string) (io.ReadCloser, error) {
+		if raw == "1_init.up.sql" {
+			return file, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	m := &source.Migration{Version: 1, Direction: source.Up, Raw: "1_init.up.sql"}
+	if _, err := d.stored(open, m); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+	if file.closes != 1 {
+		t.Errorf("expected the file to be closed once, got %d", file.closes)
+	}
+}
@@ -0,0 +1,74 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// splitMarker is the comment line separating statements explicitly.
+const splitMarker = "-- migrate:split"
+
+// Statements returns the statements of the migration with version in
+// direction, for databases that can run only one statement at a time.
+// The body is read like by ReadUp and ReadDown. If it has lines holding
+// just a -- migrate:split comment, it is split at those lines only, so
+// statements the splitter would cut, like procedures with their own
+// delimiters, can be kept together. Otherwise it is split at semicolons
+// outside of comments, quoted strings and identifiers and dollar-quoted
+// bodies. Statements are trimmed, without the separating semicolon, and
+// parts holding only comments are left out.
+func (d *Driver) Statements(version uint, direction source.Direction) ([]string, error) {
+	read := d.ReadUp
+	switch direction {
+	case source.Up:
+	case source.Down:
+		read = d.ReadDown
+	default:
+		return nil, fmt.Errorf("invalid direction '%s'", direction)
+	}
+	r, _, err := read(version)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	body, err := d.readAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return splitBody(string(body)), nil
+}
+
+// splitBody splits body at split markers if it has any, and into SQL
+// statements otherwise.
+func splitBody(body string) []string {
+	if parts, ok := splitAtMarkers(body); ok {
+		return parts
+	}
+	return splitStatements(body)
+}
+
+// splitAtMarkers splits body at the lines holding a split marker and
+// reports whether there were any.
+func splitAtMarkers(body string) ([]string, bool) {
+	var parts []string
+	var part strings.Builder
+	found := false
+	add := func() {
+		if s := strings.TrimSpace(part.String()); stripComments(s) != "" {
+			parts = append(parts, strings.TrimSuffix(s, ";"))
+		}
+		part.Reset()
+	}
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if strings.TrimSpace(line) == splitMarker {
+			found = true
+			add()
+			continue
+		}
+		part.WriteString(line)
+	}
+	add()
+	return parts, found
+}
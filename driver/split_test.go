@@ -0,0 +1,42 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestStatements(t *testing.T) {
+	d := newTestDriver(t, map[string]string{
+		"1_init.up.sql": "CREATE TABLE a (x String);\n-- seed\nINSERT INTO a VALUES ('a;b');\n",
+		"1_init.down.sql": "CREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END;\n" +
+			"  -- migrate:split\n" +
+			"DROP TABLE a;\n" +
+			"-- migrate:split\n" +
+			"-- nothing here\n",
+	})
+
+	up, err := d.Statements(1, source.Up)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"CREATE TABLE a (x String)", "-- seed\nINSERT INTO a VALUES ('a;b')"}; !reflect.DeepEqual(up, want) {
+		t.Errorf("expected %q, got %q", want, up)
+	}
+
+	down, err := d.Statements(1, source.Down)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"CREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END", "DROP TABLE a"}; !reflect.DeepEqual(down, want) {
+		t.Errorf("expected %q, got %q", want, down)
+	}
+
+	if _, err := d.Statements(2, source.Up); err == nil {
+		t.Error("expected error for missing migration")
+	}
+	if _, err := d.Statements(1, source.Direction("sideways")); err == nil {
+		t.Error("expected error for invalid direction")
+	}
+}
@@ -0,0 +1,134 @@
+package driver
+
+import "strings"
+
+// splitStatements splits body into its SQL statements at semicolons,
+// ignoring semicolons in comments, quoted strings and identifiers and
+// PostgreSQL dollar-quoted bodies. Backslashes escape quotes in
+// strings if the body only scans that way, see backslashEscapes.
+// Statements are trimmed and don't include the semicolon; parts
+// holding nothing but comments are left out.
+func splitStatements(body string) []string {
+	backslash := backslashEscapes(body)
+	var statements []string
+	start := 0
+	add := func(end int) {
+		if s := strings.TrimSpace(body[start:end]); stripComments(s) != "" {
+			statements = append(statements, s)
+		}
+	}
+	for i := 0; i < len(body); {
+		switch c := body[i]; {
+		case c == ';':
+			add(i)
+			i++
+			start = i
+		case c == '-' && strings.HasPrefix(body[i:], "--"):
+			i = skipPast(body, i+2, "\n")
+		case c == '/' && strings.HasPrefix(body[i:], "/*"):
+			i = skipPast(body, i+2, "*/")
+		case c == '\'' || c == '"' || c == '`':
+			i, _ = skipQuoted(body, i, backslash)
+		case c == '$':
+			if tag, ok := dollarTag(body, i); ok {
+				i = skipPast(body, i+len(tag), tag)
+			} else {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	add(len(body))
+	return statements
+}
+
+// skipPast returns the index after the first end in body at or after i,
+// or the length of body if there is none.
+func skipPast(body string, i int, end string) int {
+	if j := strings.Index(body[i:], end); j >= 0 {
+		return i + j + len(end)
+	}
+	return len(body)
+}
+
+// skipQuoted returns the index after the quoted string starting at i,
+// where a doubled quote stands for the quote itself, and whether the
+// closing quote was found. In PostgreSQL E'...' strings, and in strings
+// quoted with ' or " if backslash is set, as in MySQL, a backslash
+// escapes the byte following it; backquoted identifiers have no escapes.
+func skipQuoted(body string, i int, backslash bool) (int, bool) {
+	quote := body[i]
+	if quote == '\'' && isEscapeString(body, i) {
+		backslash = true
+	}
+	for i++; i < len(body); i++ {
+		switch {
+		case backslash && quote != '`' && body[i] == '\\':
+			i++
+		case body[i] != quote:
+		case i+1 < len(body) && body[i+1] == quote:
+			i++
+		default:
+			return i + 1, true
+		}
+	}
+	return len(body), false
+}
+
+// isEscapeString reports whether the quote at i opens a PostgreSQL
+// escape string, like E'it\'s'.
+func isEscapeString(body string, i int) bool {
+	return i > 0 && (body[i-1] == 'E' || body[i-1] == 'e') && (i == 1 || !isIdentifierByte(body[i-2]))
+}
+
+// backslashEscapes reports whether body is only well-formed if
+// backslashes escape quotes in all strings. Standard SQL strings like
+// 'C:\' take backslashes literally, while MySQL uses them as escapes,
+// like 'it\'s', so bodies are read the standard way unless that leaves
+// them malformed.
+func backslashEscapes(body string) bool {
+	return checkSQL(body, false) != nil && checkSQL(body, true) == nil
+}
+
+// dollarTag returns the dollar quote, like $$ or $body$, starting at i.
+// Positional parameters like $1 and identifiers containing $ aren't
+// dollar quotes.
+func dollarTag(body string, i int) (string, bool) {
+	if i > 0 && isIdentifierByte(body[i-1]) {
+		return "", false
+	}
+	for j := i + 1; j < len(body); j++ {
+		switch c := body[j]; {
+		case c == '$':
+			return body[i : j+1], true
+		case c >= '0' && c <= '9':
+			if j == i+1 {
+				return "", false
+			}
+		case !isIdentifierByte(c):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// stripComments returns the statement s without leading comments and
+// whitespace.
+func stripComments(s string) string {
+	for {
+		s = strings.TrimSpace(s)
+		switch {
+		case strings.HasPrefix(s, "--"):
+			s = s[skipPast(s, 2, "\n"):]
+		case strings.HasPrefix(s, "/*"):
+			s = s[skipPast(s, 2, "*/"):]
+		default:
+			return s
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"empty", "  \n", nil},
+		{"single without semicolon", "SELECT 1", []string{"SELECT 1"}},
+		{"several", "CREATE TABLE a();\nCREATE TABLE b();\n", []string{"CREATE TABLE a()", "CREATE TABLE b()"}},
+		{"strings", "INSERT INTO a VALUES ('x;y', 'it''s;');SELECT \"a;b\", `c;d`;", []string{"INSERT INTO a VALUES ('x;y', 'it''s;')", "SELECT \"a;b\", `c;d`"}},
+		{"comments", "-- drop; later\nSELECT 1; /* a; b */ SELECT 2;\n-- trailing;\n", []string{"-- drop; later\nSELECT 1", "/* a; b */ SELECT 2"}},
+		{"dollar quotes", "CREATE FUNCTION f() RETURNS int AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql;\nSELECT $$a;b$$;", []string{"CREATE FUNCTION f() RETURNS int AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql", "SELECT $$a;b$$"}},
+		{"parameters", "SELECT $1; SELECT a$b;", []string{"SELECT $1", "SELECT a$b"}},
+		{"backslash escapes", "INSERT INTO a VALUES ('it\\'s;');SELECT \"a\\\";b\";", []string{"INSERT INTO a VALUES ('it\\'s;')", "SELECT \"a\\\";b\""}},
+		{"literal backslashes", "INSERT INTO a VALUES ('C:\\');SELECT 1;", []string{"INSERT INTO a VALUES ('C:\\')", "SELECT 1"}},
+		{"unterminated", "SELECT 'a;", []string{"SELECT 'a;"}},
+	} {
+		if got := splitStatements(c.body); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: expected %q, got %q", c.name, c.want, got)
+		}
+	}
+}
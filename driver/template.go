@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"text/template"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// templateExt is the extension of migrations rendered as templates.
+const templateExt = ".tmpl"
+
+// WithTemplateData makes ReadUp and ReadDown render files ending in
+// .tmpl, such as 3_create_schema.up.sql.tmpl, as text/template templates
+// executed with data. Referring to a key missing from data is an error.
+//
+//	driver.WithTemplateData(map[string]interface{}{"Schema": "tenant_a"})
+//
+// turns CREATE SCHEMA {{.Schema}}; into CREATE SCHEMA tenant_a;.
+func WithTemplateData(data map[string]interface{}) Option {
+	return func(d *Driver) {
+		d.templateData = data
+	}
+}
+
+// isTemplate reports whether the file name raw is rendered as template.
+func (d *Driver) isTemplate(raw string) bool {
+	return d.templateData != nil && path.Ext(raw) == templateExt
+}
+
+// render replaces the body of a template file with its rendered content.
+func (d *Driver) render(m *source.Migration, r io.ReadCloser) (io.ReadCloser, error) {
+	if !d.isTemplate(d.withoutCompressionExt(m.Raw)) {
+		return r, nil
+	}
+	text, err := d.readAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(m.Raw).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse template %s: %v", m.Raw, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d.templateData); err != nil {
+		return nil, fmt.Errorf("unable to render template %s: %v", m.Raw, err)
+	}
+	return readCloser{Reader: d.buffered(buf.Bytes()), Closer: r}, nil
+}
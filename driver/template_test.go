@@ -0,0 +1,48 @@
+package driver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithTemplateData(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_schema.up.sql.tmpl":      "CREATE SCHEMA {{.Schema}} AUTHORIZATION {{.Role}};",
+		"1_schema.down.sql.tmpl.gz": gzipped(t, "DROP SCHEMA {{.Schema}};"),
+		"2_plain.up.sql":            "SELECT '{{.Schema}}';",
+		"3_missing.up.sql.tmpl":     "{{.Tablespace}}",
+		"4_broken.up.sql.tmpl":      "{{.Schema",
+	})
+	data := map[string]interface{}{"Schema": "tenant_a", "Role": "app"}
+	d, err := WithInstance(box, WithTemplateData(data), WithExtensions(".sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body, err := readUp(d)(1); err != nil || body != "CREATE SCHEMA tenant_a AUTHORIZATION app;" {
+		t.Errorf("unexpected rendered up body %q, %v", body, err)
+	}
+	if body, err := readDown(d)(1); err != nil || body != "DROP SCHEMA tenant_a;" {
+		t.Errorf("unexpected rendered down body %q, %v", body, err)
+	}
+	if body, err := readUp(d)(2); err != nil || body != "SELECT '{{.Schema}}';" {
+		t.Errorf("expected plain file to be served as stored, got %q, %v", body, err)
+	}
+	if _, _, err := d.ReadUp(3); err == nil || !strings.Contains(err.Error(), "Tablespace") {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+	if _, _, err := d.ReadUp(4); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestTemplatesNotRenderedByDefault(t *testing.T) {
+	box := newTestBox(map[string]string{"1_schema.up.sql.tmpl": "{{.Schema}}"})
+	d, err := WithInstance(box, WithExtensions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := readUp(d)(1); err != nil || body != "{{.Schema}}" {
+		t.Errorf("expected template to be served as stored, got %q, %v", body, err)
+	}
+}
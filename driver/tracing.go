@@ -0,0 +1,113 @@
+package driver
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Attribute is a key and value describing a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts the spans recorded by the driver. It is the part of an
+// OpenTelemetry trace.Tracer the driver needs, so one is adapted by
+// converting the attributes, without this package depending on it.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// WithTracer makes the driver record spans with t:
+//
+//	packr.prepare  building the index: count, min_version, max_version
+//	packr.read     reading a body, from ReadUp or ReadDown until the
+//	               body is closed: version, direction, identifier, file,
+//	               and size, the number of bytes read, once it is closed
+//
+// Spans of reads made with ReadUpContext and ReadDownContext are children
+// of the span in the context. The packrotel module adapts an
+// OpenTelemetry trace.Tracer.
+func WithTracer(t Tracer) Option {
+	return func(d *Driver) {
+		d.tracer = t
+	}
+}
+
+// startSpan starts a span if a tracer is configured.
+func (d *Driver) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	if d.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return d.tracer.Start(ctx, name, attrs...)
+}
+
+// readAttributes returns the attributes of the span reading m.
+func (d *Driver) readAttributes(m *source.Migration) []Attribute {
+	return []Attribute{
+		{"version", m.Version},
+		{"direction", string(m.Direction)},
+		{"identifier", m.Identifier},
+		{"file", m.Raw},
+	}
+}
+
+// indexAttributes returns the attributes describing migs.
+func indexAttributes(migs *migrations) []Attribute {
+	attrs := []Attribute{{"count", migs.count()}}
+	if n := len(migs.versions); n > 0 {
+		attrs = append(attrs, Attribute{"min_version", migs.versions[0]}, Attribute{"max_version", migs.versions[n-1]})
+	}
+	return attrs
+}
+
+// endSpan records err, if any, and ends span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
+
+// spanReader ends its span when it is closed, recording read errors
+// and the number of bytes read.
+type spanReader struct {
+	io.ReadCloser
+	span Span
+	read int64
+	once sync.Once
+}
+
+func (r *spanReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if err != nil && err != io.EOF {
+		r.span.RecordError(err)
+	}
+	return n, err
+}
+
+func (r *spanReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(func() {
+		r.span.SetAttributes(Attribute{"size", r.read})
+		endSpan(r.span, err)
+	})
+	return err
+}
@@ -0,0 +1,102 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name   string
+	parent string
+	attrs  []Attribute
+	errs   []error
+	ended  bool
+}
+
+type spanKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	span := &recordingSpan{name: name, attrs: attrs}
+	if parent, ok := ctx.Value(spanKey{}).(string); ok {
+		span.parent = parent
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, name), span
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attribute) { s.attrs = append(s.attrs, attrs...) }
+func (s *recordingSpan) RecordError(err error)            { s.errs = append(s.errs, err) }
+func (s *recordingSpan) End()                             { s.ended = true }
+
+func (s *recordingSpan) String() string {
+	var attrs []string
+	for _, a := range s.attrs {
+		attrs = append(attrs, fmt.Sprintf("%s=%v", a.Key, a.Value))
+	}
+	return s.name + " " + strings.Join(attrs, " ")
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	d := newTestDriver(t, map[string]string{
+		"1_init.up.sql":    "1 up",
+		"3_users.up.sql":   "3 up",
+		"3_users.down.sql": "3 down",
+	}, WithTracer(tracer))
+	if len(tracer.spans) != 1 || tracer.spans[0].String() != "packr.prepare count=3 min_version=1 max_version=3" || !tracer.spans[0].ended {
+		t.Fatalf("unexpected prepare spans %v", tracer.spans)
+	}
+
+	ctx := context.WithValue(context.Background(), spanKey{}, "deploy")
+	r, _, err := d.ReadDownContext(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	span := tracer.spans[1]
+	if want := "packr.read version=3 direction=down identifier=users file=3_users.down.sql"; span.String() != want {
+		t.Errorf("expected %q, got %q", want, span)
+	}
+	if span.parent != "deploy" {
+		t.Errorf("expected the span to be a child of deploy, got %q", span.parent)
+	}
+	ioutil.ReadAll(r)
+	if span.ended {
+		t.Error("expected the span to last until the body is closed")
+	}
+	r.Close()
+	r.Close()
+	if !span.ended || len(span.errs) != 0 {
+		t.Errorf("expected the span to end without errors, got %v, %v", span.ended, span.errs)
+	}
+	if want := "packr.read version=3 direction=down identifier=users file=3_users.down.sql size=6"; span.String() != want {
+		t.Errorf("expected the size read once the body is closed, got %q", span)
+	}
+}
+
+func TestWithTracerOpenError(t *testing.T) {
+	tracer := &recordingTracer{}
+	migs := []source.Migration{{Version: 1, Identifier: "a", Direction: source.Up, Raw: "1_a.up.sql"}}
+	opener := func(raw string) (io.ReadCloser, error) { return nil, errors.New("gone") }
+	d, err := WithMigrations(migs, opener, WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.ReadUp(1); err == nil {
+		t.Fatal("expected open error")
+	}
+	span := tracer.spans[len(tracer.spans)-1]
+	if span.name != "packr.read" || !span.ended || len(span.errs) != 1 {
+		t.Errorf("expected an ended read span with the error, got %v, %v, %v", span, span.ended, span.errs)
+	}
+}
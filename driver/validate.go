@@ -0,0 +1,212 @@
+package driver
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// WithRequiredHeader makes Validate check that every migration body
+// starts with a line matching re. Blank lines before the header are
+// ignored.
+func WithRequiredHeader(re *regexp.Regexp) Option {
+	return func(d *Driver) {
+		d.header = re
+	}
+}
+
+// ProblemKind classifies the problems found by Lint.
+type ProblemKind string
+
+const (
+	// ProblemDuplicate is reported when the up and down migration
+	// of a version come from differently named migrations.
+	ProblemDuplicate ProblemKind = "duplicate"
+	// ProblemGap is reported when versions are missing between
+	// two consecutive migrations.
+	ProblemGap ProblemKind = "gap"
+	// ProblemMissingDown is reported for up migrations without a down migration.
+	ProblemMissingDown ProblemKind = "missing-down"
+	// ProblemMissingUp is reported for down migrations without an up migration.
+	ProblemMissingUp ProblemKind = "missing-up"
+	// ProblemEmpty is reported for migrations without content.
+	ProblemEmpty ProblemKind = "empty"
+	// ProblemHeader is reported for migrations not starting with
+	// the header required by WithRequiredHeader.
+	ProblemHeader ProblemKind = "header"
+)
+
+// Problem describes an issue with the migrations found by Lint.
+type Problem struct {
+	Kind    ProblemKind
+	Version uint
+	// Direction is empty for problems concerning a whole version.
+	Direction source.Direction
+	// Files holds the files involved, if any.
+	Files   []string
+	Message string
+}
+
+func (p Problem) String() string {
+	where := fmt.Sprintf("version %d", p.Version)
+	if p.Direction != "" {
+		where += " " + string(p.Direction)
+	}
+	if len(p.Files) > 0 {
+		where += " (" + strings.Join(p.Files, ", ") + ")"
+	}
+	return where + ": " + p.Message
+}
+
+// ValidationError is returned by Validate and lists every problem found.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.String()
+	}
+	return "invalid migrations: " + strings.Join(msgs, "; ")
+}
+
+// Validate runs Lint and returns a *ValidationError if it finds any problem.
+func (d *Driver) Validate() error {
+	problems, err := d.Lint()
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// Lint checks the migrations for duplicate versions, gaps in the
+// numbering, up migrations without down migrations and vice versa,
+// empty files, and the policies configured through options.
+// Every migration body is read, without reporting the reads to Stats,
+// Events or the Tracer. The returned problems are sorted by version.
+//
+// Gaps are expected when versions are timestamps; callers using
+// such versions can ignore problems of kind ProblemGap.
+func (d *Driver) Lint() ([]Problem, error) {
+	if err := d.alive(); err != nil {
+		return nil, err
+	}
+	var problems []Problem
+	migs := d.list()
+	for i, m := range migs {
+		var prev *source.Migration
+		if i > 0 {
+			prev = migs[i-1]
+		}
+		isNewVersion := prev == nil || prev.Version != m.Version
+		if isNewVersion && prev != nil && m.Version > prev.Version+1 && !d.skippedBetween(prev.Version, m.Version) {
+			problems = append(problems, Problem{
+				Kind:    ProblemGap,
+				Version: m.Version,
+				Message: gapMessage(prev.Version, m.Version),
+			})
+		}
+		if !isNewVersion && prev.Identifier != m.Identifier {
+			problems = append(problems, Problem{
+				Kind:    ProblemDuplicate,
+				Version: m.Version,
+				Files:   []string{prev.Raw, m.Raw},
+				Message: "up and down migrations have different names",
+			})
+		}
+		if m.Direction == source.Up && (i+1 == len(migs) || migs[i+1].Version != m.Version) {
+			problems = append(problems, Problem{
+				Kind:      ProblemMissingDown,
+				Version:   m.Version,
+				Direction: m.Direction,
+				Files:     []string{m.Raw},
+				Message:   "no down migration",
+			})
+		}
+		if m.Direction == source.Down && isNewVersion {
+			problems = append(problems, Problem{
+				Kind:      ProblemMissingUp,
+				Version:   m.Version,
+				Direction: m.Direction,
+				Files:     []string{m.Raw},
+				Message:   "no up migration",
+			})
+		}
+
+		found, err := d.lintBody(m)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+	}
+	return problems, nil
+}
+
+// lintBody checks the content of m.
+func (d *Driver) lintBody(m *source.Migration) ([]Problem, error) {
+	r, err := d.body(m)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migration %s: %v", m.Raw, err)
+	}
+	defer r.Close()
+	data, err := d.readAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migration %s: %v", m.Raw, err)
+	}
+
+	problem := Problem{Version: m.Version, Direction: m.Direction, Files: []string{m.Raw}}
+	var problems []Problem
+	if len(bytes.TrimSpace(data)) == 0 {
+		problem.Kind, problem.Message = ProblemEmpty, "empty migration"
+		problems = append(problems, problem)
+	}
+	if d.header != nil && !d.header.MatchString(firstLine(data)) {
+		problem.Kind, problem.Message = ProblemHeader, "missing required header"
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// gapMessage describes the versions missing between prev and next.
+func gapMessage(prev, next uint) string {
+	if next == prev+2 {
+		return fmt.Sprintf("missing version %d", prev+1)
+	}
+	return fmt.Sprintf("missing versions %d to %d", prev+1, next-1)
+}
+
+// each calls fn for every migration in version order,
+// the up migration of a version before the down migration.
+func (d *Driver) each(fn func(m *source.Migration) error) error {
+	for _, m := range d.list() {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list returns all indexed migrations in version order,
+// the up migration of a version before the down migration.
+func (d *Driver) list() []*source.Migration {
+	return d.current().list()
+}
+
+// firstLine returns the first non-blank line of data.
+func firstLine(data []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			return line
+		}
+	}
+	return ""
+}
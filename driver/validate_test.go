@@ -0,0 +1,93 @@
+package driver
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidateRequiredHeader(t *testing.T) {
+	header := regexp.MustCompile(`^-- migration: [A-Z]+-[0-9]+$`)
+	d := newTestDriver(t, map[string]string{
+		"1_a.up.sql":   "-- migration: OPS-1\nCREATE TABLE a();",
+		"1_a.down.sql": "\n\n-- migration: OPS-1\nDROP TABLE a;",
+		"2_b.up.sql":   "CREATE TABLE b();",
+		"2_b.down.sql": "-- migration: nope\nDROP TABLE b;",
+		"3_c.up.sql":   "",
+	}, WithRequiredHeader(header))
+
+	err := d.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"2 up", "2 down", "3 up"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "1 ") {
+		t.Errorf("did not expect version 1 in %q", err)
+	}
+}
+
+func TestValidateWithoutPolicies(t *testing.T) {
+	d := newTestDriver(t, map[string]string{
+		"1_a.up.sql":   "anything",
+		"1_a.down.sql": "anything",
+	})
+	if err := d.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLint(t *testing.T) {
+	d := newTestDriver(t, map[string]string{
+		"1_init.up.sql":      "CREATE TABLE a();",
+		"1_init.down.sql":    "DROP TABLE a;",
+		"2_users.up.sql":     "CREATE TABLE users();",
+		"3_orders.up.sql":    "CREATE TABLE orders();",
+		"3_other.down.sql":   "DROP TABLE other;",
+		"6_cleanup.up.sql":   " \n\t",
+		"6_cleanup.down.sql": "SELECT 1;",
+		"7_orphan.down.sql":  "SELECT 1;",
+	})
+	problems, err := d.Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type key struct {
+		kind    ProblemKind
+		version uint
+	}
+	var got []key
+	for _, p := range problems {
+		got = append(got, key{p.Kind, p.Version})
+	}
+	want := []key{
+		{ProblemMissingDown, 2},
+		{ProblemDuplicate, 3},
+		{ProblemGap, 6},
+		{ProblemEmpty, 6},
+		{ProblemMissingUp, 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if problems[2].Message != "missing versions 4 to 5" {
+		t.Errorf("unexpected gap message %q", problems[2].Message)
+	}
+
+	err = d.Validate()
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	if len(verr.Problems) != len(problems) {
+		t.Errorf("expected %d problems, got %d", len(problems), len(verr.Problems))
+	}
+	if !strings.Contains(err.Error(), "version 3 (3_orders.up.sql, 3_other.down.sql)") {
+		t.Errorf("expected duplicate files in %q", err)
+	}
+}
@@ -0,0 +1,26 @@
+package driver
+
+import (
+	"strconv"
+)
+
+// WithVersionEncoder replaces the default numeric interpretation of the
+// version part of file names. encode turns the raw version, for example
+// "2023-01-31" in 2023-01-31_add_users.up.sql, into the uint used by
+// golang-migrate; it must be injective to avoid collisions.
+// decode turns a version back into its display form for FormatVersion
+// and may be nil.
+func WithVersionEncoder(encode func(raw string) (uint, error), decode func(version uint) string) Option {
+	return func(d *Driver) {
+		d.encodeVersion = encode
+		d.decodeVersion = decode
+	}
+}
+
+// FormatVersion returns the display form of version.
+func (d *Driver) FormatVersion(version uint) string {
+	if d.decodeVersion != nil {
+		return d.decodeVersion(version)
+	}
+	return strconv.FormatUint(uint64(version), 10)
+}
@@ -0,0 +1,72 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const dateLayout = "2006-01-02T1504"
+
+func encodeDate(raw string) (uint, error) {
+	t, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		return 0, err
+	}
+	return uint(t.Unix()), nil
+}
+
+func decodeDate(version uint) string {
+	return time.Unix(int64(version), 0).UTC().Format(dateLayout)
+}
+
+func TestWithVersionEncoder(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"2023-01-31T0900_users.up.sql":  "users",
+		"2023-01-31T0905_orders.up.sql": "orders",
+		"2022-12-01T0000_init.up.sql":   "init",
+		"1_numeric.up.sql":              "skipped",
+	})
+	d, err := WithInstance(box, WithVersionEncoder(encodeDate, decodeDate))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	v, err := d.First()
+	for err == nil {
+		got = append(got, d.FormatVersion(v))
+		v, err = d.Next(v)
+	}
+	want := "2022-12-01T0000 2023-01-31T0900 2023-01-31T0905"
+	if strings.Join(got, " ") != want {
+		t.Errorf("expected %s, got %v", want, got)
+	}
+}
+
+func TestWithVersionEncoderCollision(t *testing.T) {
+	truncate := func(raw string) (uint, error) {
+		var year uint
+		_, err := fmt.Sscanf(raw, "%4d", &year)
+		return year, err
+	}
+	box := newTestBox(map[string]string{
+		"2023-01-31_users.up.sql":  "users",
+		"2023-02-01_orders.up.sql": "orders",
+	})
+	_, err := WithInstance(box, WithVersionEncoder(truncate, nil))
+	if err == nil {
+		t.Fatal("expected collision error")
+	}
+	if !strings.Contains(err.Error(), "2023-01-31_users.up.sql") || !strings.Contains(err.Error(), "2023-02-01_orders.up.sql") {
+		t.Errorf("expected both files in error, got %q", err)
+	}
+}
+
+func TestFormatVersionDefault(t *testing.T) {
+	d := newTestDriver(t, map[string]string{"42_a.up.sql": ""})
+	if s := d.FormatVersion(42); s != "42" {
+		t.Errorf("expected 42, got %s", s)
+	}
+}
@@ -0,0 +1,107 @@
+package driver
+
+import "time"
+
+// WithHotReload makes the driver list its boxes again every interval
+// and rebuild the index when migrations were added, removed or renamed,
+// so a long-running process picks up new migrations without a restart.
+// It is meant for development, when packr resolves boxes from disk:
+// embedded boxes never change. A rebuild that fails, for example
+// because of a conflicting version, is logged and the previous index
+// is kept. Close stops watching.
+func WithHotReload(interval time.Duration) Option {
+	return func(d *Driver) {
+		d.reloadEvery = interval
+	}
+}
+
+// startWatch starts polling for changes if hot reload is enabled.
+func (d *Driver) startWatch() {
+	if d.reloadEvery <= 0 {
+		return
+	}
+	d.stop = make(chan struct{})
+	go d.watch(d.reloadEvery, d.stop)
+}
+
+// stopWatch stops polling for changes. It is safe to call more than once.
+func (d *Driver) stopWatch() {
+	d.stopOnce.Do(func() {
+		if d.stop != nil {
+			close(d.stop)
+		}
+	})
+}
+
+func (d *Driver) watch(interval time.Duration, stop <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			changed, err := d.reload(false)
+			if err == ErrClosed {
+				return
+			}
+			if err != nil {
+				d.logf("hot reload failed: %v", err)
+			} else if changed {
+				d.logf("hot reload: migrations changed")
+			}
+		}
+	}
+}
+
+// Reload lists the boxes of the driver again and atomically replaces
+// the index with one built from their current contents, for example
+// after a test changed the migrations on disk. Cached bodies are
+// dropped even if no file was added or removed. The checks run when
+// the driver was created, such as the manifest and numbering checks,
+// run on the new index before it replaces the old one; if building or
+// checking it fails, the driver keeps the previous index, no reader or
+// Events sees the rejected one and the error is returned. Reads already
+// in progress finish with the file they opened.
+func (d *Driver) Reload() error {
+	_, err := d.reload(true)
+	return err
+}
+
+// reload lists the origins of the driver again and swaps in a new index
+// if the set of migration files changed, or always if always is set.
+// The checks run when the driver was created are run on the new index
+// before it is swapped in; if they fail, it is discarded.
+func (d *Driver) reload(always bool) (bool, error) {
+	d.build.Lock()
+	defer d.build.Unlock()
+	if err := d.alive(); err != nil {
+		return false, err
+	}
+
+	idx, err := d.merge(d.origins)
+	if err != nil {
+		return false, err
+	}
+	changed := !sameFiles(raws(d.current().migrations), raws(idx.migrations))
+	if !changed && !always {
+		return false, nil
+	}
+	if err := d.check(idx); err != nil {
+		return false, err
+	}
+	d.swap(idx)
+	return changed, nil
+}
+
+func sameFiles(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+package driver
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mustWriteFile(t, dir, "1_init.up.sql", "init")
+
+	d, err := WithFS(os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := d.reload(false); err != nil || changed {
+		t.Fatalf("expected no change, got %v, %v", changed, err)
+	}
+
+	mustWriteFile(t, dir, "2_users.up.sql", "users")
+	if changed, err := d.reload(false); err != nil || !changed {
+		t.Fatalf("expected a change, got %v, %v", changed, err)
+	}
+	if body, err := readUp(d)(2); err != nil || body != "users" {
+		t.Errorf("expected new migration, got %q, %v", body, err)
+	}
+
+	mustWriteFile(t, dir, "2_other.up.sql", "conflict")
+	if _, err := d.reload(false); err == nil {
+		t.Fatal("expected error for conflicting migrations")
+	}
+	if body, err := readUp(d)(2); err != nil || body != "users" {
+		t.Errorf("expected previous index to be kept, got %q, %v", body, err)
+	}
+}
+
+func TestReloadChangedBodies(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, dir, "1_init.up.sql", "init")
+	mustWriteFile(t, dir, "2_users.up.sql", "users")
+	d, err := WithFS(os.DirFS(dir), WithBodyCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := readUp(d)
+	if body, err := read(1); err != nil || body != "init" {
+		t.Fatalf("expected init, got %q, %v", body, err)
+	}
+
+	mustWriteFile(t, dir, "1_init.up.sql", "changed")
+	if err := os.Remove(filepath.Join(dir, "2_users.up.sql")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if body, err := read(1); err != nil || body != "changed" {
+		t.Errorf("expected the changed body, got %q, %v", body, err)
+	}
+	if _, err := d.Next(1); err != os.ErrNotExist {
+		t.Errorf("expected the removed migration to be gone, got %v", err)
+	}
+
+	mustWriteFile(t, dir, "1_other.up.sql", "conflict")
+	if err := d.Reload(); err == nil {
+		t.Fatal("expected error for conflicting migrations")
+	}
+	if body, err := read(1); err != nil || body != "changed" {
+		t.Errorf("expected previous index to be kept, got %q, %v", body, err)
+	}
+}
+
+func TestOpenWithReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mustWriteFile(t, dir, "1_init.up.sql", "init")
+
+	d, err := (&Driver{}).Open("packr://" + dir + "?reload=" + url.QueryEscape("10ms"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if _, err := d.Next(1); err != os.ErrNotExist {
+		t.Fatalf("expected a single migration, got %v", err)
+	}
+
+	mustWriteFile(t, dir, "2_users.up.sql", "users")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if v, err := d.Next(1); err == nil && v == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("new migration not picked up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&Driver{}).Open("packr://" + dir + "?reload=soon"); err == nil {
+		t.Fatal("expected error for invalid interval")
+	}
+}
+
+func TestReloadRestoresRepeatables(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, dir, "1_init.up.sql", "init")
+	mustWriteFile(t, dir, "R__views.sql", "views")
+	d, err := WithFS(os.DirFS(dir), WithManifest(Manifest{"1_init.up.sql": sha("init"), "R__views.sql": sha("views")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, dir, "2_users.up.sql", "users")
+	if _, err := d.reload(false); err == nil {
+		t.Fatal("expected the manifest check to fail")
+	}
+	if repeatables, err := d.Repeatables(); err != nil || len(repeatables) != 1 {
+		t.Errorf("expected the previous repeatables to be restored, got %v, %v", repeatables, err)
+	}
+}
+
+func TestReloadChecksBeforeSwapping(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, dir, "1_init.up.sql", "init")
+	events := &recordingEvents{}
+	d, err := WithFS(os.DirFS(dir), WithEvents(events), WithManifest(Manifest{"1_init.up.sql": sha("init")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.prepares) != 1 {
+		t.Fatalf("expected one prepare event, got %d", len(events.prepares))
+	}
+	old := d.current().migrations
+	mustWriteFile(t, dir, "2_users.up.sql", "users")
+	if err := d.Reload(); err == nil {
+		t.Fatal("expected the manifest check to fail")
+	}
+	if d.current().migrations != old {
+		t.Error("expected the rejected index never to be swapped in")
+	}
+	if len(events.prepares) != 1 {
+		t.Errorf("expected no prepare event for the rejected index, got %d", len(events.prepares))
+	}
+}
+
+func TestRejectedReloadIsNeverVisible(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, dir, "1_init.up.sql", "init")
+	d, err := WithFS(os.DirFS(dir), WithManifest(Manifest{"1_init.up.sql": sha("init")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, dir, "2_users.up.sql", "users")
+
+	done := make(chan struct{})
+	seen := make(chan error, 1)
+	go func() {
+		defer close(seen)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := d.Next(1); err != os.ErrNotExist {
+				seen <- err
+				return
+			}
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		if err := d.Reload(); err == nil {
+			t.Fatal("expected the manifest check to fail")
+		}
+	}
+	close(done)
+	if err, ok := <-seen; ok {
+		t.Errorf("expected the rejected migration never to be visible, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+// Package drivertest provides a conformance suite for source drivers
+// serving migrations from box-like inputs, such as the packr driver
+// and wrappers around it.
+package drivertest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	st "github.com/golang-migrate/migrate/v4/source/testing"
+)
+
+// Files maps migration file names, like 1_init.up.sql, to their bodies.
+type Files map[string]string
+
+// Opener returns a driver serving the migrations in files.
+// It should fail t if the driver can't be created.
+type Opener func(t *testing.T, files Files) source.Driver
+
+// Test runs the suite against drivers created by open, one per case:
+// the fixture of the golang-migrate source driver tests, an empty box,
+// migrations with only an up or only a down direction, and versions
+// that aren't contiguous.
+func Test(t *testing.T, open Opener) {
+	t.Run("golang-migrate", func(t *testing.T) {
+		d := open(t, Files{
+			"1_foobar.up.sql":   "1 up",
+			"1_foobar.down.sql": "1 down",
+			"3_foobar.up.sql":   "3 up",
+			"4_foobar.up.sql":   "4 up",
+			"4_foobar.down.sql": "4 down",
+			"5_foobar.down.sql": "5 down",
+			"7_foobar.up.sql":   "7 up",
+			"7_foobar.down.sql": "7 down",
+		})
+		defer d.Close()
+		st.Test(t, d)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		d := open(t, Files{})
+		defer d.Close()
+		if _, err := d.First(); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("First: expected os.ErrNotExist, got %v", err)
+		}
+		for _, v := range []uint{0, 1} {
+			expectNotExist(t, d, v, true, true)
+			if _, err := d.Prev(v); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Prev(%d): expected os.ErrNotExist, got %v", v, err)
+			}
+			if _, err := d.Next(v); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Next(%d): expected os.ErrNotExist, got %v", v, err)
+			}
+		}
+	})
+
+	t.Run("single direction", func(t *testing.T) {
+		d := open(t, Files{
+			"1_only_up.up.sql":     "1 up",
+			"2_only_down.down.sql": "2 down",
+		})
+		defer d.Close()
+		expectSequence(t, d, 1, 2)
+		expectBody(t, d, 1, true, "only_up", "1 up")
+		expectNotExist(t, d, 1, false, true)
+		expectBody(t, d, 2, false, "only_down", "2 down")
+		expectNotExist(t, d, 2, true, false)
+	})
+
+	t.Run("non-contiguous", func(t *testing.T) {
+		d := open(t, Files{
+			"3_a.up.sql":      "3 up",
+			"3_a.down.sql":    "3 down",
+			"10_b.up.sql":     "10 up",
+			"1000_c.up.sql":   "1000 up",
+			"1000_c.down.sql": "1000 down",
+		})
+		defer d.Close()
+		expectSequence(t, d, 3, 10, 1000)
+		for _, v := range []uint{0, 1, 4, 9, 11, 999, 1001} {
+			expectNotExist(t, d, v, true, true)
+		}
+		if v, err := d.Prev(10); err != nil || v != 3 {
+			t.Errorf("Prev(10): expected 3, got %d, %v", v, err)
+		}
+		if v, err := d.Next(10); err != nil || v != 1000 {
+			t.Errorf("Next(10): expected 1000, got %d, %v", v, err)
+		}
+		expectBody(t, d, 1000, false, "c", "1000 down")
+	})
+}
+
+// expectSequence checks that the driver has exactly the given versions.
+func expectSequence(t *testing.T, d source.Driver, versions ...uint) {
+	t.Helper()
+	v, err := d.First()
+	if err != nil || v != versions[0] {
+		t.Fatalf("First: expected %d, got %d, %v", versions[0], v, err)
+	}
+	for _, want := range versions[1:] {
+		next, err := d.Next(v)
+		if err != nil || next != want {
+			t.Fatalf("Next(%d): expected %d, got %d, %v", v, want, next, err)
+		}
+		if prev, err := d.Prev(next); err != nil || prev != v {
+			t.Errorf("Prev(%d): expected %d, got %d, %v", next, v, prev, err)
+		}
+		v = next
+	}
+	if _, err := d.Next(v); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Next(%d): expected os.ErrNotExist, got %v", v, err)
+	}
+	if _, err := d.Prev(versions[0]); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Prev(%d): expected os.ErrNotExist, got %v", versions[0], err)
+	}
+}
+
+// expectBody checks the identifier and body of a migration.
+func expectBody(t *testing.T, d source.Driver, version uint, up bool, identifier, body string) {
+	t.Helper()
+	r, id, err := read(d, version, up)
+	if err != nil {
+		t.Errorf("%s: unexpected error %v", name(version, up), err)
+		return
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Errorf("%s: unable to read body: %v", name(version, up), err)
+	}
+	if id != identifier || string(data) != body {
+		t.Errorf("%s: expected %q, %q, got %q, %q", name(version, up), identifier, body, id, data)
+	}
+}
+
+// expectNotExist checks that the driver has no migration for version
+// in the given directions.
+func expectNotExist(t *testing.T, d source.Driver, version uint, up, down bool) {
+	t.Helper()
+	for _, dir := range []bool{true, false} {
+		if (dir && !up) || (!dir && !down) {
+			continue
+		}
+		r, _, err := read(d, version, dir)
+		if err == nil {
+			r.Close()
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s: expected os.ErrNotExist, got %v", name(version, dir), err)
+		}
+	}
+}
+
+func read(d source.Driver, version uint, up bool) (io.ReadCloser, string, error) {
+	if up {
+		return d.ReadUp(version)
+	}
+	return d.ReadDown(version)
+}
+
+func name(version uint, up bool) string {
+	if up {
+		return fmt.Sprintf("ReadUp(%d)", version)
+	}
+	return fmt.Sprintf("ReadDown(%d)", version)
+}
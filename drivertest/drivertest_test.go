@@ -0,0 +1,24 @@
+package drivertest_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/fiskeben/packr-source-driver/driver"
+	"github.com/fiskeben/packr-source-driver/drivertest"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+func TestDriver(t *testing.T) {
+	drivertest.Test(t, func(t *testing.T, files drivertest.Files) source.Driver {
+		fsys := fstest.MapFS{}
+		for name, body := range files {
+			fsys["db/"+name] = &fstest.MapFile{Data: []byte(body)}
+		}
+		d, err := driver.WithFS(fsys, driver.WithRoot("db"), driver.WithStrictParsing())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	})
+}
@@ -1,9 +1,10 @@
 module github.com/fiskeben/packr-source-driver
 
-go 1.15
+go 1.16
 
 require (
 	github.com/gobuffalo/packr v1.11.1
 	github.com/golang-migrate/migrate/v4 v4.14.1
 	github.com/stretchr/testify v1.7.0 // indirect
+	golang.org/x/text v0.3.8
 )
@@ -0,0 +1,11 @@
+module github.com/fiskeben/packr-source-driver/migrator
+
+go 1.16
+
+require (
+	github.com/fiskeben/packr-source-driver v0.0.0
+	github.com/gobuffalo/packr v1.11.1
+	github.com/golang-migrate/migrate/v4 v4.14.1
+)
+
+replace github.com/fiskeben/packr-source-driver => ../
@@ -0,0 +1,54 @@
+// Package migrator creates golang-migrate instances reading their
+// migrations from packr boxes, the glue every service otherwise copies.
+//
+// It is a module of its own, so the driver doesn't depend on the
+// golang-migrate package and its dependencies. The database driver must
+// still be imported by the program:
+//
+//	import _ "github.com/golang-migrate/migrate/v4/database/postgres"
+//
+//	m, err := migrator.NewMigrator(packr.NewBox("./migrations"), os.Getenv("DATABASE_URL"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer m.Close()
+//	if err := migrator.Up(m); err != nil {
+//		log.Fatal(err)
+//	}
+package migrator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fiskeben/packr-source-driver/driver"
+	"github.com/gobuffalo/packr"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// NewMigrator returns a migrate instance applying the migrations of box,
+// configured with opts, to the database at databaseURL. Errors reading
+// the box and connecting to the database are kept apart, so callers can
+// tell a broken build from an unavailable database, and unwrap to the
+// errors of the driver and of golang-migrate.
+func NewMigrator(box packr.Box, databaseURL string, opts ...driver.Option) (*migrate.Migrate, error) {
+	src, err := driver.WithInstance(box, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+	m, err := migrate.NewWithSourceInstance("packr", src, databaseURL)
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("unable to connect to database: %w", err)
+	}
+	return m, nil
+}
+
+// Up applies all pending migrations with m. Unlike m.Up, it doesn't
+// fail when the database is already up to date.
+func Up(m *migrate.Migrate) error {
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("unable to migrate database: %w", err)
+	}
+	return nil
+}
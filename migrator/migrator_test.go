@@ -0,0 +1,55 @@
+package migrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gobuffalo/packr"
+	_ "github.com/golang-migrate/migrate/v4/database/stub"
+)
+
+func newTestBox(files map[string]string) packr.Box {
+	box := packr.NewBox("./testdata/none")
+	for name, body := range files {
+		box.AddString(name, body)
+	}
+	return box
+}
+
+func TestNewMigrator(t *testing.T) {
+	box := newTestBox(map[string]string{
+		"1_init.up.sql":  "CREATE TABLE a();",
+		"2_users.up.sql": "CREATE TABLE users();",
+	})
+	m, err := NewMigrator(box, "stub://")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if err := Up(m); err != nil {
+		t.Fatal(err)
+	}
+	version, dirty, err := m.Version()
+	if err != nil || dirty || version != 2 {
+		t.Errorf("expected clean version 2, got %d, %v, %v", version, dirty, err)
+	}
+	if err := Up(m); err != nil {
+		t.Errorf("expected no error without pending migrations, got %v", err)
+	}
+}
+
+func TestNewMigratorErrors(t *testing.T) {
+	conflicting := newTestBox(map[string]string{
+		"1_init.up.sql":  "",
+		"1_other.up.sql": "",
+	})
+	if _, err := NewMigrator(conflicting, "stub://"); err == nil || !strings.HasPrefix(err.Error(), "unable to read migrations") {
+		t.Errorf("expected error reading migrations, got %v", err)
+	}
+
+	box := newTestBox(map[string]string{"1_init.up.sql": ""})
+	if _, err := NewMigrator(box, "nosuchdb://"); err == nil || !strings.HasPrefix(err.Error(), "unable to connect to database") {
+		t.Errorf("expected error connecting to database, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+module github.com/fiskeben/packr-source-driver/packrotel
+
+go 1.20
+
+require (
+	github.com/fiskeben/packr-source-driver v0.0.0
+	github.com/golang-migrate/migrate/v4 v4.14.1
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/gobuffalo/packr v1.11.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	golang.org/x/text v0.3.8 // indirect
+)
+
+replace github.com/fiskeben/packr-source-driver => ../
@@ -0,0 +1,82 @@
+// Package packrotel records the spans of the driver with an
+// OpenTelemetry tracer:
+//
+//	d, err := driver.WithInstance(box, driver.WithTracer(packrotel.NewTracer(otel.Tracer("migrations"))))
+//
+// It is a module of its own, so the driver doesn't depend on
+// OpenTelemetry.
+package packrotel
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/fiskeben/packr-source-driver/driver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracer returns a driver.Tracer starting its spans with t.
+func NewTracer(t trace.Tracer) driver.Tracer {
+	return tracer{t}
+}
+
+type tracer struct {
+	t trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, name string, attrs ...driver.Attribute) (context.Context, driver.Span) {
+	ctx, s := t.t.Start(ctx, name, trace.WithAttributes(convert(attrs)...))
+	return ctx, span{s}
+}
+
+// span records errors with an error status as well, so failed reads
+// stand out in trace views.
+type span struct {
+	s trace.Span
+}
+
+func (s span) SetAttributes(attrs ...driver.Attribute) {
+	s.s.SetAttributes(convert(attrs)...)
+}
+
+func (s span) RecordError(err error) {
+	s.s.RecordError(err)
+	s.s.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() {
+	s.s.End()
+}
+
+// convert returns attrs as OpenTelemetry attributes. Values of types
+// OpenTelemetry doesn't support are formatted as strings.
+func convert(attrs []driver.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, a := range attrs {
+		kvs[i] = keyValue(a.Key, a.Value)
+	}
+	return kvs
+}
+
+func keyValue(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return attribute.Int64(key, int64(v))
+		}
+	case float64:
+		return attribute.Float64(key, v)
+	}
+	return attribute.String(key, fmt.Sprint(value))
+}
@@ -0,0 +1,70 @@
+package packrotel
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/fiskeben/packr-source-driver/driver"
+	"github.com/golang-migrate/migrate/v4/source"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("migrations"))
+	d, err := driver.WithFiles(map[string][]byte{"1_init.up.sql": []byte("CREATE TABLE a();")}, driver.WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(r)
+	r.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "packr.prepare" || spans[1].Name() != "packr.read" {
+		t.Fatalf("expected a prepare and a read span, got %v", spans)
+	}
+	want := map[attribute.Key]attribute.Value{
+		"version":    attribute.Int64Value(1),
+		"direction":  attribute.StringValue("up"),
+		"identifier": attribute.StringValue("init"),
+		"file":       attribute.StringValue("1_init.up.sql"),
+		"size":       attribute.Int64Value(17),
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[1].Attributes() {
+		got[kv.Key] = kv.Value
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("expected %s=%s, got %s", key, value.Emit(), got[key].Emit())
+		}
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("migrations"))
+	migs := []source.Migration{{Version: 1, Identifier: "a", Direction: source.Up, Raw: "1_a.up.sql"}}
+	opener := func(raw string) (io.ReadCloser, error) { return nil, errors.New("gone") }
+	d, err := driver.WithMigrations(migs, opener, driver.WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.ReadUp(1); err == nil {
+		t.Fatal("expected open error")
+	}
+	spans := recorder.Ended()
+	read := spans[len(spans)-1]
+	if read.Status().Code != codes.Error || len(read.Events()) != 1 {
+		t.Errorf("expected the error to be recorded, got %v, %v", read.Status(), read.Events())
+	}
+}
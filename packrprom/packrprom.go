@@ -0,0 +1,59 @@
+// Package packrprom exports the Stats of a driver to a Prometheus
+// registry, under the names Stats.WriteTo uses:
+//
+//	prometheus.MustRegister(packrprom.NewCollector(d))
+//
+// It is a module of its own, so the driver doesn't depend on the
+// Prometheus client library. Labels telling several drivers apart can
+// be added with prometheus.WrapRegistererWith.
+package packrprom
+
+import (
+	"github.com/fiskeben/packr-source-driver/driver"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	indexed = prometheus.NewDesc("packr_migrations_indexed",
+		"Number of indexed migrations.", nil, nil)
+	reads = prometheus.NewDesc("packr_migration_reads_total",
+		"Migration bodies served by direction.", []string{"direction"}, nil)
+	readBytes = prometheus.NewDesc("packr_migration_read_bytes_total",
+		"Bytes read from served migration bodies.", nil, nil)
+	parseFailures = prometheus.NewDesc("packr_migration_parse_failures_total",
+		"Files skipped because they couldn't be parsed.", nil, nil)
+	bufferedBytes = prometheus.NewDesc("packr_migration_buffered_bytes_total",
+		"Bytes of migration bodies copied into memory.", nil, nil)
+	cachedBytes = prometheus.NewDesc("packr_migration_cached_bytes",
+		"Bytes of migration bodies held by the body cache.", nil, nil)
+)
+
+// Collector is a prometheus.Collector reporting the Stats of a driver
+// every time it is collected.
+type Collector struct {
+	d *driver.Driver
+}
+
+// NewCollector returns a Collector for d.
+func NewCollector(d *driver.Driver) *Collector {
+	return &Collector{d: d}
+}
+
+// Describe sends the descriptors of the metrics of c to ch.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{indexed, reads, readBytes, parseFailures, bufferedBytes, cachedBytes} {
+		ch <- desc
+	}
+}
+
+// Collect sends the current Stats of the driver to ch.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.d.Stats()
+	ch <- prometheus.MustNewConstMetric(indexed, prometheus.GaugeValue, float64(s.Migrations))
+	ch <- prometheus.MustNewConstMetric(reads, prometheus.CounterValue, float64(s.UpReads), "up")
+	ch <- prometheus.MustNewConstMetric(reads, prometheus.CounterValue, float64(s.DownReads), "down")
+	ch <- prometheus.MustNewConstMetric(readBytes, prometheus.CounterValue, float64(s.ReadBytes))
+	ch <- prometheus.MustNewConstMetric(parseFailures, prometheus.CounterValue, float64(s.ParseFailures))
+	ch <- prometheus.MustNewConstMetric(bufferedBytes, prometheus.CounterValue, float64(s.BufferedBytes))
+	ch <- prometheus.MustNewConstMetric(cachedBytes, prometheus.GaugeValue, float64(s.CachedBytes))
+}
@@ -0,0 +1,49 @@
+package packrprom
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/fiskeben/packr-source-driver/driver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	d, err := driver.WithFiles(map[string][]byte{
+		"1_init.up.sql":   []byte("CREATE TABLE a();"),
+		"1_init.down.sql": []byte("DROP TABLE a;"),
+		"notes.sql":       []byte(""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := d.ReadUp(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(r)
+	r.Close()
+
+	c := NewCollector(d)
+	if problems, err := testutil.CollectAndLint(c); err != nil || len(problems) != 0 {
+		t.Errorf("expected no lint problems, got %v, %v", problems, err)
+	}
+	// The collected metrics match those written by Stats.WriteTo.
+	var text bytes.Buffer
+	if _, err := d.Stats().WriteTo(&text); err != nil {
+		t.Fatal(err)
+	}
+	if err := testutil.CollectAndCompare(c, &text); err != nil {
+		t.Error(err)
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reg.Gather(); err != nil {
+		t.Errorf("expected consistent metrics, got %v", err)
+	}
+}